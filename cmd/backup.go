@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashiiiii/airules/pkg/installer"
+	"github.com/spf13/cobra"
+)
+
+// backupTimeLayout is how backup times are shown and passed to restore --at.
+const backupTimeLayout = "20060102_150405"
+
+// newBackupCmd returns the backup command.
+func newBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Manage backups",
+		Long: "Manage the backups of files replaced by install, which are kept in the backups directory of the config directory.\n\n" +
+			"The commands work on the backups of the project in the working directory and of global files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cmd.Help(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error displaying help: %v\n", err)
+			}
+		},
+	}
+
+	cmd.AddCommand(newBackupListCmd())
+	cmd.AddCommand(newBackupRestoreCmd())
+	cmd.AddCommand(newBackupPruneCmd())
+
+	return cmd
+}
+
+// newBackupListCmd returns the command that lists backups.
+func newBackupListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List backups",
+		Long:  "List the backups of the files in the project in the working directory and of global files",
+		Run: func(cmd *cobra.Command, args []string) {
+			backups, err := installer.ListBackups()
+			if err != nil {
+				fmt.Printf("Failed to list backups: %v\n", err)
+
+				return
+			}
+
+			if len(backups) == 0 {
+				fmt.Println("No backups found")
+
+				return
+			}
+
+			for _, backup := range backups {
+				fmt.Printf("%s  %s  %s\n", backup.Time.Format(backupTimeLayout), backup.Path, backup.BackupPath)
+			}
+		},
+	}
+}
+
+// newBackupRestoreCmd returns the command that restores a file from a backup.
+func newBackupRestoreCmd() *cobra.Command {
+	var atFlag string
+
+	cmd := &cobra.Command{
+		Use:   "restore <path>",
+		Short: "Restore a file from a backup",
+		Long: "Restore a file from its most recent backup, or from the backup made at the time given with --at. " +
+			"The current file is backed up first.",
+		Example: `  # Restore CLAUDE.md from its most recent backup
+  airules backup restore CLAUDE.md
+
+  # Restore CLAUDE.md from the backup listed with the time 20250102_150405
+  airules backup restore CLAUDE.md --at 20250102_150405`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			backup, err := installer.RestoreBackup(args[0], atFlag)
+			if err != nil {
+				fmt.Printf("Failed to restore backup: %v\n", err)
+
+				return
+			}
+
+			fmt.Printf("Restored %s from the backup made at %s\n", args[0], backup.Time.Format(backupTimeLayout))
+		},
+	}
+
+	cmd.Flags().StringVar(&atFlag, "at", "", "Time of the backup to restore, as shown by 'airules backup list'")
+
+	return cmd
+}
+
+// newBackupPruneCmd returns the command that removes old backups.
+func newBackupPruneCmd() *cobra.Command {
+	var keepFlag int
+	var olderThanFlag string
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old backups",
+		Long: "Remove the backups of each file except the newest ones kept with --keep. " +
+			"With --older-than, only backups older than the given age are removed. " +
+			"Backups that uninstall restores are never removed.",
+		Example: `  # Keep only the 3 newest backups of each file
+  airules backup prune --keep 3
+
+  # Remove backups older than 30 days, keeping at least the newest one of each file
+  airules backup prune --keep 1 --older-than 30d`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !cmd.Flags().Changed("keep") && !cmd.Flags().Changed("older-than") {
+				fmt.Println("Error: Specify which backups to remove with --keep and/or --older-than")
+
+				return
+			}
+			if keepFlag < 0 {
+				fmt.Println("Error: --keep must not be negative")
+
+				return
+			}
+
+			olderThan, err := parseAge(olderThanFlag)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+
+				return
+			}
+
+			removed, err := installer.PruneBackups(installer.PruneOptions{Keep: keepFlag, OlderThan: olderThan})
+			for _, backup := range removed {
+				fmt.Printf("Removed %s\n", backup.BackupPath)
+			}
+			if err != nil {
+				fmt.Printf("Failed to prune backups: %v\n", err)
+
+				return
+			}
+
+			fmt.Printf("Removed %d backups\n", len(removed))
+		},
+	}
+
+	cmd.Flags().IntVar(&keepFlag, "keep", 0, "Number of newest backups of each file to keep")
+	cmd.Flags().StringVar(&olderThanFlag, "older-than", "", "Only remove backups older than this age, such as 30d or 12h")
+
+	return cmd
+}
+
+// parseAge parses an age such as 30d or 12h. Days are supported in addition to the units of time.ParseDuration.
+func parseAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age '%s'", value)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age '%s'", value)
+	}
+
+	return age, nil
+}
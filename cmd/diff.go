@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hashiiiii/airules/pkg/diff"
+	"github.com/hashiiiii/airules/pkg/installer"
+	"github.com/spf13/cobra"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// newDiffCmd returns the diff command.
+func newDiffCmd() *cobra.Command {
+	var editorFlag string
+	var modeFlag string
+	var setFlag string
+	var varFlags []string
+	var exitCodeFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show what install would change",
+		Long: "Show unified diffs between the installed rules-for-ai files and what install would write, without changing anything.\n\n" +
+			"The exit status is 2 when the diff can't be computed, and with --exit-code it's 1 when there are differences.",
+		Example: `  # Show the changes to both local and global rules for Windsurf
+  airules diff -e windsurf
+
+  # Fail when the local Cursor rules are out of date, e.g. in CI
+  airules diff -e cursor -m local --exit-code`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			installType, ok := resolveInstallType(editorFlag, modeFlag)
+			if !ok {
+				return &ExitError{Code: 2}
+			}
+
+			vars, err := parseVars(varFlags)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+
+				return &ExitError{Code: 2}
+			}
+
+			changes, err := installer.Plan(editorFlag, installType, installer.Options{Key: setFlag, Vars: vars})
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+
+				return &ExitError{Code: 2}
+			}
+
+			changed := false
+			for _, change := range changes {
+				if !change.Changed() && !change.Conflict {
+					continue
+				}
+				changed = true
+
+				oldName := change.Path
+				if !change.Exists {
+					oldName = "/dev/null"
+				}
+				newName := fmt.Sprintf("%s\t(%s %s)", change.Path, editorFlag, change.Mode)
+				fmt.Print(diff.Unified(oldName, newName, change.Old, change.New, diffContext))
+				if change.Conflict {
+					fmt.Printf("%s (%s) has merge conflicts to resolve\n", change.Path, change.Mode)
+				}
+			}
+
+			if !changed {
+				fmt.Printf("No changes for %s editor\n", editorFlag)
+
+				return nil
+			}
+
+			if exitCodeFlag {
+				return &ExitError{Code: 1}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&editorFlag, "editor", "e", "", "Editor to compare rules for (required)")
+	cmd.Flags().StringVarP(
+		&modeFlag,
+		"mode",
+		"m",
+		"",
+		fmt.Sprintf("Mode to compare rules for: '%s', '%s', or both if not specified", modeLocal, modeGlobal),
+	)
+	cmd.Flags().StringVarP(&setFlag, "set", "k", "default", "Rule set to compare, as defined in config.toml")
+	cmd.Flags().StringArrayVar(&varFlags, "var", nil, "Template variable as key=value, overriding [vars] in config.toml (repeatable)")
+	cmd.Flags().BoolVar(&exitCodeFlag, "exit-code", false, "Exit with status 1 when there are differences")
+	if err := cmd.MarkFlagRequired("editor"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'editor' flag as required: %v", err))
+	}
+
+	return cmd
+}
@@ -0,0 +1,13 @@
+package cmd
+
+import "fmt"
+
+// ExitError makes airules exit with Code without printing help.
+// Commands return it after reporting the problem themselves.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
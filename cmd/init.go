@@ -4,18 +4,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
 
 	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/hashiiiii/airules/templates"
+	"github.com/mitchellh/go-homedir"
 	"github.com/otiai10/copy"
 	"github.com/spf13/cobra"
 )
 
 func newInitCmd() *cobra.Command {
+	var templatesDirFlag string
+
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize airules configuration",
-		Long:  "Create the configuration directory and default files",
+		Long: `Create the configuration directory and default files.
+
+The default templates are built into airules and are written to ~/.config/airules/templates.
+Use --templates-dir to copy templates from an external directory instead.`,
+		Example: `  # Write the built-in templates
+  airules init
+
+  # Copy templates from a shared directory
+  airules init --templates-dir ~/work/team-rules/templates`,
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get config directory
 			configDir, err := config.GetConfigDir()
@@ -32,36 +43,43 @@ func newInitCmd() *cobra.Command {
 				return
 			}
 
-			// Get repository root directory
-			_, filename, _, ok := runtime.Caller(0)
-			if !ok {
-				fmt.Println("Failed to get current file path")
+			// Destination templates directory
+			destTemplatesDir, err := config.GetTemplatesDir()
+			if err != nil {
+				fmt.Printf("Failed to get templates directory: %v\n", err)
 
 				return
 			}
 
-			// Go up two directories from cmd/init.go to reach repository root
-			repoRoot := filepath.Dir(filepath.Dir(filename))
+			if templatesDirFlag != "" {
+				// Copy templates from the external directory
+				srcTemplatesDir, err := homedir.Expand(templatesDirFlag)
+				if err != nil {
+					fmt.Printf("Failed to resolve templates directory: %v\n", err)
 
-			// Source templates directory (in repository root)
-			srcTemplatesDir := filepath.Join(repoRoot, "templates")
+					return
+				}
 
-			// Check if templates directory exists
-			if _, err := os.Stat(srcTemplatesDir); os.IsNotExist(err) {
-				fmt.Printf("'templates' directory not found at %s\n", srcTemplatesDir)
+				if _, err := os.Stat(srcTemplatesDir); os.IsNotExist(err) {
+					fmt.Printf("Templates directory not found at %s\n", srcTemplatesDir)
 
-				return
-			}
+					return
+				}
 
-			// Destination templates directory
-			destTemplatesDir := filepath.Join(configDir, "templates")
+				fmt.Printf("Copying templates from %s to %s\n", srcTemplatesDir, destTemplatesDir)
+				if err := copy.Copy(srcTemplatesDir, destTemplatesDir); err != nil {
+					fmt.Printf("Failed to copy templates: %v\n", err)
 
-			// Copy templates
-			fmt.Printf("Copying templates from %s to %s\n", srcTemplatesDir, destTemplatesDir)
-			if err := copy.Copy(srcTemplatesDir, destTemplatesDir); err != nil {
-				fmt.Printf("Failed to copy templates: %v\n", err)
+					return
+				}
+			} else {
+				// Write the templates embedded in the binary
+				fmt.Printf("Writing built-in templates to %s\n", destTemplatesDir)
+				if err := templates.Extract(destTemplatesDir); err != nil {
+					fmt.Printf("Failed to write templates: %v\n", err)
 
-				return
+					return
+				}
 			}
 			fmt.Println("Templates copied successfully.")
 
@@ -84,5 +102,13 @@ func newInitCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVarP(
+		&templatesDirFlag,
+		"templates-dir",
+		"t",
+		"",
+		"Copy templates from this directory instead of using the built-in templates",
+	)
+
 	return cmd
 }
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/hashiiiii/airules/pkg/installer"
+	"github.com/hashiiiii/airules/pkg/plugin"
 	"github.com/spf13/cobra"
 )
 
@@ -17,11 +19,15 @@ const (
 func newInstallCmd() *cobra.Command {
 	var editorFlag string
 	var modeFlag string
+	var setFlag string
+	var varFlags []string
+	var dryRunFlag bool
+	var skipUnchangedBackupsFlag bool
 
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Install rules-for-ai files",
-		Long:  "Install rules-for-ai files for AI-powered editors like Windsurf and Cursor",
+		Long:  "Install rules-for-ai files for AI-powered editors.",
 		Example: `  # Install both local and global rules for Windsurf
   airules install -e windsurf
 
@@ -29,60 +35,63 @@ func newInstallCmd() *cobra.Command {
   airules install -e cursor -m local
 
   # Install only global rules for Windsurf
-  airules install -e windsurf -m global`,
-		Run: func(cmd *cobra.Command, args []string) {
-			// Check if editor is specified
-			if editorFlag == "" {
-				fmt.Println("Error: Editor must be specified using -e/--editor flag")
-				fmt.Println("Supported editors:", strings.Join(installer.GetSupportedEditors(), ", "))
-
-				return
+  airules install -e windsurf -m global
+
+  # Install the "backend" rule set for Cursor
+  airules install -e cursor -k backend
+
+  # Set a template variable used by the rule files
+  airules install -e claude --var team=platform
+
+  # Show which files would be written without changing anything
+  airules install -e cursor --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			installType, ok := resolveInstallType(editorFlag, modeFlag)
+			if !ok {
+				return nil
 			}
 
-			// Check if editor is supported
-			if !installer.IsEditorSupported(editorFlag) {
-				fmt.Printf("Error: Unsupported editor '%s'\n", editorFlag)
-				fmt.Println("Supported editors:", strings.Join(installer.GetSupportedEditors(), ", "))
+			vars, err := parseVars(varFlags)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
 
-				return
+				return nil
 			}
 
-			// Determine installation type based on mode flag
-			var installType installer.InstallType
-			switch modeFlag {
-			case modeLocal:
-				installType = installer.Local
-			case modeGlobal:
-				// グローバルモードが指定されたがサポートされていない場合はエラー
-				if !installer.IsGlobalModeSupported(editorFlag) {
-					fmt.Printf("Error: Editor '%s' does not support global mode installation through files\n", editorFlag)
-					fmt.Println("Global rules for this editor must be set through the editor's settings interface")
-
-					return
-				}
-				installType = installer.Global
-			case "":
-				// Default to both modes if not specified
-				installType = installer.All
-			default:
-				fmt.Printf("Error: Invalid mode '%s'. Valid values are '%s' or '%s'\n", modeFlag, modeLocal, modeGlobal)
-
-				return
+			opts := installer.Options{Key: setFlag, Vars: vars, SkipUnchangedBackups: skipUnchangedBackupsFlag}
+			if dryRunFlag {
+				return printPlan(editorFlag, installType, opts)
 			}
 
 			// Display information about the installation
-			fmt.Printf("Installing %s rules for %s editor...\n", getInstallTypeLabel(installType, editorFlag), editorFlag)
+			fmt.Printf(
+				"Installing %s rules for %s editor using rule set '%s'...\n",
+				getInstallTypeLabel(installType, editorFlag),
+				editorFlag,
+				setFlag,
+			)
 
 			// Install rules
-			err := installer.Install(editorFlag, installType)
+			err = installer.InstallWithOptions(editorFlag, installType, opts)
+			var conflictErr *installer.ConflictError
+			if errors.As(err, &conflictErr) {
+				fmt.Printf("Installed rules for %s editor with merge conflicts in:\n", editorFlag)
+				for _, path := range conflictErr.Paths {
+					fmt.Printf("  %s\n", path)
+				}
+
+				return &ExitError{Code: 1}
+			}
 			if err != nil {
 				fmt.Printf("Error during installation: %v\n", err)
 
-				return
+				return nil
 			}
 
 			// Success message
 			fmt.Printf("Successfully installed rules for %s editor\n", editorFlag)
+
+			return nil
 		},
 	}
 
@@ -95,13 +104,123 @@ func newInstallCmd() *cobra.Command {
 		"",
 		fmt.Sprintf("Mode to install rules for: '%s', '%s', or both if not specified", modeLocal, modeGlobal),
 	)
+	cmd.Flags().StringVarP(&setFlag, "set", "k", "default", "Rule set to install, as defined in config.toml")
+	cmd.Flags().StringArrayVar(&varFlags, "var", nil, "Template variable as key=value, overriding [vars] in config.toml (repeatable)")
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show which files would be written without changing anything")
+	cmd.Flags().BoolVar(
+		&skipUnchangedBackupsFlag,
+		"skip-unchanged-backups",
+		false,
+		"Don't back up files whose content is the same as their most recent backup",
+	)
 	if err := cmd.MarkFlagRequired("editor"); err != nil {
 		panic(fmt.Sprintf("failed to mark 'editor' flag as required: %v", err))
 	}
 
+	// The supported editors are only listed when the help is shown, since discovering plugins runs their executables
+	defaultHelp := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		c.Long = installLong()
+		defaultHelp(c, args)
+	})
+
 	return cmd
 }
 
+// installLong returns the long description of the install command.
+func installLong() string {
+	return fmt.Sprintf(
+		"Install rules-for-ai files for AI-powered editors.\n\nSupported editors: %s\n\n"+
+			"Editors can be added with executables named %s<name> on PATH or in the plugins directory of the config directory.",
+		strings.Join(installer.GetSupportedEditors(), ", "),
+		plugin.ExecutablePrefix,
+	)
+}
+
+// resolveInstallType validates the editor and mode flags and returns the install type.
+// The problem is printed and false is returned when they're invalid.
+func resolveInstallType(editorFlag, modeFlag string) (installer.InstallType, bool) {
+	// Check if editor is specified
+	if editorFlag == "" {
+		fmt.Println("Error: Editor must be specified using -e/--editor flag")
+		fmt.Println("Supported editors:", strings.Join(installer.GetSupportedEditors(), ", "))
+
+		return 0, false
+	}
+
+	// Check if editor is supported
+	if !installer.IsEditorSupported(editorFlag) {
+		fmt.Printf("Error: Unsupported editor '%s'\n", editorFlag)
+		fmt.Println("Supported editors:", strings.Join(installer.GetSupportedEditors(), ", "))
+
+		return 0, false
+	}
+
+	// Determine installation type based on mode flag
+	switch modeFlag {
+	case modeLocal:
+		return installer.Local, true
+	case modeGlobal:
+		// グローバルモードが指定されたがサポートされていない場合はエラー
+		if !installer.IsGlobalModeSupported(editorFlag) {
+			fmt.Printf("Error: Editor '%s' does not support global mode installation through files\n", editorFlag)
+			fmt.Println(installer.GetGlobalUnsupportedReason(editorFlag))
+
+			return 0, false
+		}
+
+		return installer.Global, true
+	case "":
+		// Default to both modes if not specified
+		return installer.All, true
+	default:
+		fmt.Printf("Error: Invalid mode '%s'. Valid values are '%s' or '%s'\n", modeFlag, modeLocal, modeGlobal)
+
+		return 0, false
+	}
+}
+
+// printPlan prints the files that installing would write.
+func printPlan(editorFlag string, installType installer.InstallType, opts installer.Options) error {
+	changes, err := installer.Plan(editorFlag, installType, opts)
+	if err != nil {
+		fmt.Printf("Error during installation: %v\n", err)
+
+		return nil
+	}
+
+	for _, change := range changes {
+		switch {
+		case !change.Exists:
+			fmt.Printf("Would create %s (%s)\n", change.Path, change.Mode)
+		case change.Conflict && !change.Changed():
+			fmt.Printf("%s (%s) has unresolved merge conflicts\n", change.Path, change.Mode)
+		case change.Conflict:
+			fmt.Printf("Would update %s (%s) with merge conflicts\n", change.Path, change.Mode)
+		case change.Changed():
+			fmt.Printf("Would update %s (%s)\n", change.Path, change.Mode)
+		default:
+			fmt.Printf("%s (%s) is up to date\n", change.Path, change.Mode)
+		}
+	}
+
+	return nil
+}
+
+// parseVars parses key=value template variables.
+func parseVars(values []string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable '%s', expected key=value", value)
+		}
+		vars[key] = val
+	}
+
+	return vars, nil
+}
+
 // getInstallTypeLabel returns a human-readable label for the install type.
 func getInstallTypeLabel(installType installer.InstallType, editor string) string {
 	switch installType {
@@ -29,8 +29,12 @@ func NewRootCmd() *cobra.Command {
 
 	// Add subcommands
 	cmd.AddCommand(newInstallCmd())
+	cmd.AddCommand(newDiffCmd())
+	cmd.AddCommand(newUninstallCmd())
+	cmd.AddCommand(newBackupCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newInitCmd())
+	cmd.AddCommand(newSetsCmd())
 
 	return cmd
 }
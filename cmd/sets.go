@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// newSetsCmd returns the sets command.
+func newSetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sets",
+		Short: "Manage rule sets",
+		Long:  "Manage the named rule sets defined in config.toml",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cmd.Help(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error displaying help: %v\n", err)
+			}
+		},
+	}
+
+	cmd.AddCommand(newSetsListCmd())
+
+	return cmd
+}
+
+// newSetsListCmd returns the command that lists rule sets per editor and mode.
+func newSetsListCmd() *cobra.Command {
+	var editorFlag string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List rule sets",
+		Long:  "List the rule-set keys defined in config.toml for each editor and mode",
+		Example: `  # List rule sets for all editors
+  airules sets list
+
+  # List rule sets for Cursor only
+  airules sets list -e cursor`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Failed to load config: %v\n", err)
+
+				return
+			}
+
+			editors := make([]string, 0, len(cfg.Editors))
+			for editor := range cfg.Editors {
+				if editorFlag == "" || editor == editorFlag {
+					editors = append(editors, editor)
+				}
+			}
+			sort.Strings(editors)
+
+			if len(editors) == 0 {
+				fmt.Printf("Error: No rule sets configured for editor '%s'\n", editorFlag)
+
+				return
+			}
+
+			for _, editor := range editors {
+				fmt.Println(editor)
+				for _, mode := range []string{modeLocal, modeGlobal} {
+					keys, err := cfg.RuleSetKeys(editor, mode)
+					if err != nil {
+						fmt.Printf("  %-7s error: %v\n", mode+":", err)
+
+						continue
+					}
+					if len(keys) == 0 {
+						continue
+					}
+					fmt.Printf("  %-7s %s\n", mode+":", strings.Join(keys, ", "))
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&editorFlag, "editor", "e", "", "Only list rule sets for this editor")
+
+	return cmd
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hashiiiii/airules/pkg/installer"
+	"github.com/spf13/cobra"
+)
+
+// newUninstallCmd returns the uninstall command.
+func newUninstallCmd() *cobra.Command {
+	var editorFlag string
+	var modeFlag string
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall rules-for-ai files",
+		Long: "Remove the rules-for-ai files installed for an editor, as recorded in the install manifests.\n\n" +
+			"Managed blocks are removed from files that also hold other content. Other files are removed and replaced by " +
+			"their most recent backup, and local directories left empty are removed. Files modified since they were " +
+			"installed are kept unless --force is given.",
+		Example: `  # Uninstall both local and global rules for Windsurf
+  airules uninstall -e windsurf
+
+  # Uninstall only local rules for Cursor
+  airules uninstall -e cursor -m local`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var installType installer.InstallType
+			switch modeFlag {
+			case modeLocal:
+				installType = installer.Local
+			case modeGlobal:
+				installType = installer.Global
+			case "":
+				installType = installer.All
+			default:
+				fmt.Printf("Error: Invalid mode '%s'. Valid values are '%s' or '%s'\n", modeFlag, modeLocal, modeGlobal)
+
+				return nil
+			}
+
+			err := installer.Uninstall(editorFlag, installType, installer.UninstallOptions{Force: forceFlag})
+			if err != nil {
+				fmt.Printf("Error during uninstallation: %v\n", err)
+
+				return nil
+			}
+
+			fmt.Printf("Successfully uninstalled rules for %s editor\n", editorFlag)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&editorFlag, "editor", "e", "", "Editor to uninstall rules for (required)")
+	cmd.Flags().StringVarP(
+		&modeFlag,
+		"mode",
+		"m",
+		"",
+		fmt.Sprintf("Mode to uninstall rules for: '%s', '%s', or both if not specified", modeLocal, modeGlobal),
+	)
+	cmd.Flags().BoolVar(&forceFlag, "force", false, "Remove files even if they were modified since they were installed")
+	if err := cmd.MarkFlagRequired("editor"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'editor' flag as required: %v", err))
+	}
+
+	return cmd
+}
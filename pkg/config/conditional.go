@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Conditional adds rule files to every rule set when a technology is detected in the project.
+type Conditional struct {
+	// When is the detected tag that enables the rule files, such as go, docker or react.
+	When string `toml:"when"`
+	// Files are the rule files to add, relative to the config directory.
+	Files []string `toml:"files"`
+	// Editors limits the rule files to these editors; they apply to every editor when it's empty.
+	Editors []string `toml:"editors,omitempty"`
+	// Modes are the modes the rule files apply to; they apply to local mode when it's empty.
+	Modes []string `toml:"modes,omitempty"`
+}
+
+// applies reports whether the conditional rule files are added for the editor, mode and detected tags.
+func (c Conditional) applies(editor, mode string, detected []string) bool {
+	if !slices.Contains(detected, c.When) {
+		return false
+	}
+	if len(c.Editors) > 0 && !slices.Contains(c.Editors, editor) {
+		return false
+	}
+
+	modes := c.Modes
+	if len(modes) == 0 {
+		modes = []string{"local"}
+	}
+
+	return slices.Contains(modes, mode)
+}
+
+// ConditionalRuleFiles returns the rule files of the conditionals that apply to the editor, mode and detected tags.
+func (c *Config) ConditionalRuleFiles(editor, mode string, detected []string) []string {
+	var files []string
+	for _, conditional := range c.Conditionals {
+		if conditional.applies(editor, mode, detected) {
+			files = append(files, conditional.Files...)
+		}
+	}
+
+	return files
+}
+
+// GetConditionalRuleFilePaths returns the absolute paths of the conditional rule files
+// for the editor, mode and detected tags.
+func GetConditionalRuleFilePaths(editor, mode string, detected []string) ([]string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return toAbsolutePaths(config.ConditionalRuleFiles(editor, mode, detected))
+}
+
+// validateConditionals checks that the conditionals are complete.
+func (c *Config) validateConditionals() error {
+	for i, conditional := range c.Conditionals {
+		if conditional.When == "" {
+			return fmt.Errorf("conditional #%d has no 'when'", i+1)
+		}
+		if len(conditional.Files) == 0 {
+			return fmt.Errorf("conditional '%s' has no files", conditional.When)
+		}
+		for _, mode := range conditional.Modes {
+			if mode != "local" && mode != "global" {
+				return fmt.Errorf("conditional '%s' has an invalid mode '%s'", conditional.When, mode)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Config_ConditionalRuleFiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Conditionals: []Conditional{
+			{When: "go", Files: []string{"templates/fragments/go.md"}},
+			{When: "docker", Files: []string{"templates/fragments/docker.md"}, Editors: []string{"claude"}},
+			{When: "go", Files: []string{"templates/fragments/go-global.md"}, Modes: []string{"global"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		editor   string
+		mode     string
+		detected []string
+		want     []string
+	}{
+		{name: "Nothing detected", editor: "cursor", mode: "local", want: nil},
+		{name: "Local mode by default", editor: "cursor", mode: "local", detected: []string{"go"}, want: []string{"templates/fragments/go.md"}},
+		{
+			name:     "Limited to an editor",
+			editor:   "claude",
+			mode:     "local",
+			detected: []string{"go", "docker"},
+			want:     []string{"templates/fragments/go.md", "templates/fragments/docker.md"},
+		},
+		{name: "Other editors", editor: "cursor", mode: "local", detected: []string{"docker"}, want: nil},
+		{name: "Global mode", editor: "cursor", mode: "global", detected: []string{"go"}, want: []string{"templates/fragments/go-global.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, cfg.ConditionalRuleFiles(tt.editor, tt.mode, tt.detected))
+		})
+	}
+}
+
+func Test_Config_validateConditionals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		conditionals []Conditional
+		wantErr      bool
+	}{
+		{name: "Valid", conditionals: []Conditional{{When: "go", Files: []string{"go.md"}, Modes: []string{"local", "global"}}}},
+		{name: "Missing when", conditionals: []Conditional{{Files: []string{"go.md"}}}, wantErr: true},
+		{name: "Missing files", conditionals: []Conditional{{When: "go"}}, wantErr: true},
+		{name: "Invalid mode", conditionals: []Conditional{{When: "go", Files: []string{"go.md"}, Modes: []string{"all"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{Conditionals: tt.conditionals}
+			err := cfg.validateConditionals()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
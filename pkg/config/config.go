@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/BurntSushi/toml"
 	"github.com/mitchellh/go-homedir"
@@ -12,12 +13,41 @@ import (
 // Config represents the application configuration.
 type Config struct {
 	Editors map[string]EditorConfig `toml:"editors"`
+	// CustomEditors declares editors that are not built into airules.
+	CustomEditors []EditorDefinition `toml:"custom_editors,omitempty"`
+	// Vars are user-defined variables available to rule templates as .Vars.
+	Vars map[string]string `toml:"vars,omitempty"`
+	// Conditionals add rule files when technologies are detected in the project.
+	Conditionals []Conditional `toml:"conditional,omitempty"`
+}
+
+// EditorDefinition declares a custom editor and where its rule files are installed.
+// Paths may contain {key}, which is replaced with the rule-set key, and global paths may start with ~.
+type EditorDefinition struct {
+	Name string `toml:"name"`
+	// LocalPath is the directory of the local rule file, relative to the project root.
+	LocalPath string `toml:"local_path"`
+	// GlobalPath maps an OS name (darwin, linux, windows or default) to the directory of the global rule file.
+	GlobalPath map[string]string `toml:"global_path,omitempty"`
+	// FileName is the name of the rule file.
+	FileName string `toml:"file_name"`
+	// GlobalFileName is the name of the global rule file, if it differs from FileName.
+	GlobalFileName string `toml:"global_file_name,omitempty"`
+	// Format selects how combined rule files are separated: text, markdown or plain.
+	Format          string `toml:"format,omitempty"`
+	GlobalSupported bool   `toml:"global_supported"`
+	// ManagedBlock writes the rules into a managed block, keeping hand-written content in the rule file.
+	ManagedBlock bool `toml:"managed_block,omitempty"`
 }
 
 // EditorConfig represents editor-specific configuration.
 type EditorConfig struct {
-	Local  map[string][]string `toml:"local"`
-	Global map[string][]string `toml:"global"`
+	Local  map[string]RuleSet `toml:"local"`
+	Global map[string]RuleSet `toml:"global"`
+	// Nested maps rule-set keys to project subdirectories and the rule files installed there.
+	Nested map[string]map[string][]string `toml:"nested,omitempty"`
+	// Options holds editor-specific settings, such as the context file name for Gemini CLI.
+	Options map[string]string `toml:"options,omitempty"`
 }
 
 // GetDefaultConfig returns the default configuration.
@@ -25,19 +55,83 @@ func GetDefaultConfig() *Config {
 	return &Config{
 		Editors: map[string]EditorConfig{
 			"windsurf": {
-				Local: map[string][]string{
-					"default": {"templates/windsurf/local/.windsurfrules"},
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/windsurf/local/.windsurfrules"}},
 				},
-				Global: map[string][]string{
-					"default": {"templates/windsurf/global/global_rules.md"},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/windsurf/global/global_rules.md"}},
 				},
 			},
 			"cursor": {
-				Local: map[string][]string{
-					"default": {"templates/cursor/local/project_rules.mdc"},
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/cursor/local/project_rules.mdc"}},
+				},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/cursor/global/global_rules.mdc"}},
+				},
+			},
+			"claude": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/claude/local/CLAUDE.md"}},
+				},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/claude/global/CLAUDE.md"}},
+				},
+			},
+			"copilot": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{
+						"templates/copilot/local/copilot-instructions.md",
+						"templates/copilot/local/tests.instructions.md",
+					}},
+				},
+			},
+			"agents": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/agents/local/AGENTS.md"}},
+				},
+			},
+			"gemini": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/gemini/local/GEMINI.md"}},
+				},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/gemini/global/GEMINI.md"}},
+				},
+			},
+			"junie": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/junie/local/guidelines.md"}},
+				},
+			},
+			"zed": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/zed/local/.rules"}},
+				},
+			},
+			"aider": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/aider/local/CONVENTIONS.md"}},
 				},
-				Global: map[string][]string{
-					"default": {"templates/cursor/global/global_rules.mdc"},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/aider/global/CONVENTIONS.md"}},
+				},
+			},
+			"cline": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/cline/local/rules.md"}},
+				},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/cline/global/rules.md"}},
+				},
+			},
+			"roo": {
+				Local: map[string]RuleSet{
+					"default": {Files: []string{"templates/roo/local/rules.md"}},
+					"code":    {Files: []string{"templates/roo/local/rules-code.md"}},
+				},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/roo/global/rules.md"}},
 				},
 			},
 		},
@@ -55,6 +149,36 @@ func GetConfigDir() (string, error) {
 	return configDir, nil
 }
 
+// GetTemplatesDir returns the directory that holds the rule templates.
+func GetTemplatesDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "templates"), nil
+}
+
+// GetStateDir returns the directory where airules keeps track of what it installed.
+func GetStateDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "state"), nil
+}
+
+// GetBackupsDir returns the directory where airules keeps backups of the files it replaced.
+func GetBackupsDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "backups"), nil
+}
+
 // EnsureConfigDir creates the configuration directory if it doesn't exist.
 func EnsureConfigDir() (string, error) {
 	configDir, err := GetConfigDir()
@@ -99,9 +223,47 @@ func LoadConfig() (*Config, error) {
 		config.Editors = make(map[string]EditorConfig)
 	}
 
+	// Fill in editors added after the config file was created
+	for editor, editorConfig := range GetDefaultConfig().Editors {
+		if _, ok := config.Editors[editor]; !ok {
+			config.Editors[editor] = editorConfig
+		}
+	}
+
+	if err := config.validateCustomEditors(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+
+	if err := config.validateConditionals(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+
 	return &config, nil
 }
 
+// validateCustomEditors checks that the custom editor definitions are complete and uniquely named.
+func (c *Config) validateCustomEditors() error {
+	names := make(map[string]bool, len(c.CustomEditors))
+	for i, definition := range c.CustomEditors {
+		if definition.Name == "" {
+			return fmt.Errorf("custom editor #%d has no name", i+1)
+		}
+		if names[definition.Name] {
+			return fmt.Errorf("custom editor '%s' is defined more than once", definition.Name)
+		}
+		names[definition.Name] = true
+
+		if definition.FileName == "" {
+			return fmt.Errorf("custom editor '%s' has no file_name", definition.Name)
+		}
+		if definition.GlobalSupported && len(definition.GlobalPath) == 0 {
+			return fmt.Errorf("custom editor '%s' supports global mode but has no global_path", definition.Name)
+		}
+	}
+
+	return nil
+}
+
 // SaveConfig saves the configuration to file.
 func SaveConfig(config *Config) error {
 	configDir, err := EnsureConfigDir()
@@ -131,28 +293,111 @@ func GetRuleFilePaths(editor, mode, key string) ([]string, error) {
 		return nil, err
 	}
 
-	// Check if editor exists
-	editorConfig, ok := config.Editors[editor]
+	ruleFiles, err := config.RuleFiles(editor, mode, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAbsolutePaths(ruleFiles)
+}
+
+// IsTemplated reports whether the rule files of the editor's rule set for the mode and key are rendered as templates.
+func IsTemplated(editor, mode, key string) (bool, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	return config.Templated(editor, mode, key)
+}
+
+// NestedRuleFiles returns the rule files installed in project subdirectories for the editor and key.
+func (c *Config) NestedRuleFiles(editor, key string) (map[string][]string, error) {
+	editorConfig, ok := c.Editors[editor]
 	if !ok {
 		return nil, fmt.Errorf("editor '%s' not found", editor)
 	}
 
-	var ruleFiles []string
+	nested := editorConfig.Nested[key]
+	for dir := range nested {
+		if !filepath.IsLocal(dir) {
+			return nil, fmt.Errorf("nested directory '%s' for %s must be relative to the project root", dir, editor)
+		}
+	}
+
+	return nested, nil
+}
 
-	// Get rule file names from config based on mode
-	switch mode {
-	case "local":
-		ruleFiles, ok = editorConfig.Local[key]
-	case "global":
-		ruleFiles, ok = editorConfig.Global[key]
-	default:
-		return nil, fmt.Errorf("invalid mode '%s'", mode)
+// GetNestedRuleFilePaths returns the absolute rule file paths per project subdirectory for the editor and key.
+func GetNestedRuleFilePaths(editor, key string) (map[string][]string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	nested, err := config.NestedRuleFiles(editor, key)
+	if err != nil {
+		return nil, err
+	}
+
+	nestedPaths := make(map[string][]string, len(nested))
+	for dir, ruleFiles := range nested {
+		absolutePaths, err := toAbsolutePaths(ruleFiles)
+		if err != nil {
+			return nil, err
+		}
+		nestedPaths[dir] = absolutePaths
+	}
+
+	return nestedPaths, nil
+}
+
+// GetCustomEditors returns the custom editors defined in config.toml without creating the file if it's missing.
+func GetCustomEditors() ([]EditorDefinition, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(configDir, "config.toml")); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.CustomEditors, nil
+}
+
+// GetEditorOptions returns the editor-specific options configured for the editor.
+func GetEditorOptions(editor string) (map[string]string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
 	}
 
+	editorConfig, ok := config.Editors[editor]
 	if !ok {
-		return nil, fmt.Errorf("rule key '%s' not found for %s %s", key, editor, mode)
+		return nil, fmt.Errorf("editor '%s' not found", editor)
 	}
 
+	return editorConfig.Options, nil
+}
+
+// GetVars returns the template variables defined in config.toml.
+func GetVars() (map[string]string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Vars, nil
+}
+
+// toAbsolutePaths converts rule file paths relative to the config directory to absolute paths.
+func toAbsolutePaths(ruleFiles []string) ([]string, error) {
 	// Get config directory
 	configDir, err := GetConfigDir()
 	if err != nil {
@@ -168,6 +413,50 @@ func GetRuleFilePaths(editor, mode, key string) ([]string, error) {
 	return absolutePaths, nil
 }
 
+// ruleSets returns the rule sets defined for the editor and mode.
+func (c *Config) ruleSets(editor, mode string) (map[string]RuleSet, error) {
+	editorConfig, ok := c.Editors[editor]
+	if !ok {
+		return nil, fmt.Errorf("editor '%s' not found", editor)
+	}
+
+	switch mode {
+	case "local":
+		return editorConfig.Local, nil
+	case "global":
+		return editorConfig.Global, nil
+	default:
+		return nil, fmt.Errorf("invalid mode '%s'", mode)
+	}
+}
+
+// RuleSetKeys returns the sorted rule-set keys defined for the editor and mode.
+func (c *Config) RuleSetKeys(editor, mode string) ([]string, error) {
+	ruleSets, err := c.ruleSets(editor, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(ruleSets))
+	for key := range ruleSets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// HasRuleSet reports whether the rule-set key is defined for the editor and mode.
+func (c *Config) HasRuleSet(editor, mode, key string) bool {
+	ruleSets, err := c.ruleSets(editor, mode)
+	if err != nil {
+		return false
+	}
+	_, ok := ruleSets[key]
+
+	return ok
+}
+
 // GetSupportedEditors returns a list of supported editors.
 func GetSupportedEditors() []string {
 	config, err := LoadConfig()
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_RuleSetKeys(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Editors: map[string]EditorConfig{
+			"cursor": {
+				Local: map[string]RuleSet{
+					"frontend": {Files: []string{"templates/cursor/local/frontend.mdc"}},
+					"default":  {Files: []string{"templates/cursor/local/project_rules.mdc"}},
+					"backend":  {Files: []string{"templates/cursor/local/backend.mdc"}},
+				},
+				Global: map[string]RuleSet{
+					"default": {Files: []string{"templates/cursor/global/global_rules.mdc"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		editor  string
+		mode    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Local keys are sorted", editor: "cursor", mode: "local", want: []string{"backend", "default", "frontend"}},
+		{name: "Global keys", editor: "cursor", mode: "global", want: []string{"default"}},
+		{name: "Unknown editor", editor: "unknown", mode: "local", wantErr: true},
+		{name: "Invalid mode", editor: "cursor", mode: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.RuleSetKeys(tt.editor, tt.mode)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Config_HasRuleSet(t *testing.T) {
+	t.Parallel()
+
+	cfg := GetDefaultConfig()
+
+	tests := []struct {
+		name   string
+		editor string
+		mode   string
+		key    string
+		want   bool
+	}{
+		{name: "Key defined for the mode", editor: "roo", mode: "local", key: "code", want: true},
+		{name: "Key only defined for the other mode", editor: "roo", mode: "global", key: "code", want: false},
+		{name: "Unknown editor", editor: "unknown", mode: "local", key: "default", want: false},
+		{name: "Invalid mode", editor: "roo", mode: "unknown", key: "default", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, cfg.HasRuleSet(tt.editor, tt.mode, tt.key))
+		})
+	}
+}
+
+func Test_Config_NestedRuleFiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Editors: map[string]EditorConfig{
+			"agents": {
+				Local: map[string]RuleSet{"default": {Files: []string{"templates/agents/local/AGENTS.md"}}},
+				Nested: map[string]map[string][]string{
+					"default": {"services/api": {"templates/agents/local/api.md"}},
+					"escape":  {"../outside": {"templates/agents/local/api.md"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		editor  string
+		key     string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name:   "Nested rule files for a key",
+			editor: "agents",
+			key:    "default",
+			want:   map[string][]string{"services/api": {"templates/agents/local/api.md"}},
+		},
+		{name: "No nested rule files for a key", editor: "agents", key: "backend", want: nil},
+		{name: "Directory outside the project", editor: "agents", key: "escape", wantErr: true},
+		{name: "Unknown editor", editor: "unknown", key: "default", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.NestedRuleFiles(tt.editor, tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Config_validateCustomEditors(t *testing.T) {
+	t.Parallel()
+
+	valid := EditorDefinition{
+		Name:            "internal",
+		LocalPath:       ".internal",
+		FileName:        "rules.md",
+		GlobalPath:      map[string]string{"default": "~/.internal"},
+		GlobalSupported: true,
+	}
+
+	tests := []struct {
+		name        string
+		definitions []EditorDefinition
+		wantErr     bool
+	}{
+		{name: "No custom editors", definitions: nil},
+		{name: "Valid custom editor", definitions: []EditorDefinition{valid}},
+		{name: "Missing name", definitions: []EditorDefinition{{FileName: "rules.md"}}, wantErr: true},
+		{name: "Duplicate name", definitions: []EditorDefinition{valid, valid}, wantErr: true},
+		{name: "Missing file name", definitions: []EditorDefinition{{Name: "internal"}}, wantErr: true},
+		{
+			name:        "Global mode without a global path",
+			definitions: []EditorDefinition{{Name: "internal", FileName: "rules.md", GlobalSupported: true}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{CustomEditors: tt.definitions}
+			err := cfg.validateCustomEditors()
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RuleSet lists the rule files of a rule-set key.
+// In config.toml it's either an array of rule files or a table with files, the key it extends and template.
+type RuleSet struct {
+	Files []string
+	// Extends is the key of the rule set whose files are installed before Files.
+	Extends string
+	// Template renders the rule files as Go templates. Other rule files are installed as they are,
+	// so rules that show template syntax such as {{ }} don't need escaping.
+	Template bool
+}
+
+// UnmarshalTOML decodes a rule set from an array of files or a table with files, extends and template.
+func (r *RuleSet) UnmarshalTOML(data any) error {
+	switch value := data.(type) {
+	case []any:
+		files, err := toStrings(value)
+		if err != nil {
+			return err
+		}
+		*r = RuleSet{Files: files}
+
+		return nil
+	case map[string]any:
+		var ruleSet RuleSet
+		for name, field := range value {
+			switch name {
+			case "files":
+				list, ok := field.([]any)
+				if !ok {
+					return fmt.Errorf("rule set files must be an array of strings")
+				}
+				files, err := toStrings(list)
+				if err != nil {
+					return err
+				}
+				ruleSet.Files = files
+			case "extends":
+				extends, ok := field.(string)
+				if !ok {
+					return fmt.Errorf("rule set extends must be a string")
+				}
+				ruleSet.Extends = extends
+			case "template":
+				template, ok := field.(bool)
+				if !ok {
+					return fmt.Errorf("rule set template must be a boolean")
+				}
+				ruleSet.Template = template
+			default:
+				return fmt.Errorf("unknown rule set field '%s'", name)
+			}
+		}
+		*r = ruleSet
+
+		return nil
+	default:
+		return fmt.Errorf("rule set must be an array of files or a table with files, extends and template")
+	}
+}
+
+// MarshalTOML encodes a rule set as an array of files, or as an inline table if it extends another or is a template.
+func (r RuleSet) MarshalTOML() ([]byte, error) {
+	files := r.Files
+	if files == nil {
+		files = []string{}
+	}
+
+	// JSON strings, booleans and arrays of strings are valid TOML
+	encodedFiles, err := json.Marshal(files)
+	if err != nil {
+		return nil, err
+	}
+	if r.Extends == "" && !r.Template {
+		return encodedFiles, nil
+	}
+
+	var fields []string
+	if r.Extends != "" {
+		encodedExtends, err := json.Marshal(r.Extends)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, "extends = "+string(encodedExtends))
+	}
+	fields = append(fields, "files = "+string(encodedFiles))
+	if r.Template {
+		fields = append(fields, "template = true")
+	}
+
+	return []byte("{ " + strings.Join(fields, ", ") + " }"), nil
+}
+
+// toStrings converts a decoded TOML array to strings.
+func toStrings(values []any) ([]string, error) {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("rule set files must be strings, got %v", value)
+		}
+		strs = append(strs, str)
+	}
+
+	return strs, nil
+}
+
+// RuleFiles returns the rule files of the editor's rule set for the mode and key,
+// starting with the files of the rule sets it extends.
+func (c *Config) RuleFiles(editor, mode, key string) ([]string, error) {
+	chain, err := c.ruleSetChain(editor, mode, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, ruleSet := range chain {
+		files = append(slices.Clone(ruleSet.Files), files...)
+	}
+
+	return files, nil
+}
+
+// Templated reports whether the rule files of the editor's rule set for the mode and key are rendered as templates,
+// which they are when the rule set or one it extends sets template.
+func (c *Config) Templated(editor, mode, key string) (bool, error) {
+	chain, err := c.ruleSetChain(editor, mode, key)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(chain, func(ruleSet RuleSet) bool { return ruleSet.Template }), nil
+}
+
+// ruleSetChain returns the editor's rule set for the mode and key followed by the rule sets it extends.
+func (c *Config) ruleSetChain(editor, mode, key string) ([]RuleSet, error) {
+	ruleSets, err := c.ruleSets(editor, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	var chain []RuleSet
+	for current := key; current != ""; {
+		if slices.Contains(keys, current) {
+			cycle := strings.Join(append(keys, current), " -> ")
+
+			return nil, fmt.Errorf("rule sets extend each other for %s %s: %s", editor, mode, cycle)
+		}
+		keys = append(keys, current)
+
+		ruleSet, ok := ruleSets[current]
+		if !ok {
+			if current == key {
+				return nil, fmt.Errorf("rule key '%s' not found for %s %s", key, editor, mode)
+			}
+
+			return nil, fmt.Errorf("rule key '%s' extended by '%s' not found for %s %s", current, keys[len(keys)-2], editor, mode)
+		}
+
+		chain = append(chain, ruleSet)
+		current = ruleSet.Extends
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RuleSet_TOML(t *testing.T) {
+	t.Parallel()
+
+	input := `
+[editors.cursor.local]
+default = ["templates/cursor/local/project_rules.mdc"]
+backend = { extends = "default", files = ["templates/cursor/local/backend.mdc"] }
+frontend = { files = ["templates/cursor/local/frontend.mdc"], template = true }
+`
+	var cfg Config
+	_, err := toml.Decode(input, &cfg)
+	require.NoError(t, err)
+
+	want := map[string]RuleSet{
+		"default":  {Files: []string{"templates/cursor/local/project_rules.mdc"}},
+		"backend":  {Files: []string{"templates/cursor/local/backend.mdc"}, Extends: "default"},
+		"frontend": {Files: []string{"templates/cursor/local/frontend.mdc"}, Template: true},
+	}
+	assert.Equal(t, want, cfg.Editors["cursor"].Local)
+
+	var buf bytes.Buffer
+	require.NoError(t, toml.NewEncoder(&buf).Encode(cfg))
+	assert.Contains(t, buf.String(), `default = ["templates/cursor/local/project_rules.mdc"]`)
+
+	var decoded Config
+	_, err = toml.Decode(buf.String(), &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, want, decoded.Editors["cursor"].Local)
+}
+
+func Test_RuleSet_UnmarshalTOML_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Not an array or table", input: `default = "rules.md"`},
+		{name: "Non-string file", input: `default = [1]`},
+		{name: "Unknown field", input: `default = { files = [], base = "default" }`},
+		{name: "Non-string extends", input: `default = { extends = 1 }`},
+		{name: "Non-boolean template", input: `default = { files = [], template = "yes" }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ruleSets map[string]RuleSet
+			_, err := toml.Decode(tt.input, &ruleSets)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Config_RuleFiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Editors: map[string]EditorConfig{
+			"claude": {
+				Local: map[string]RuleSet{
+					"default":  {Files: []string{"base.md"}},
+					"backend":  {Files: []string{"backend.md"}, Extends: "default"},
+					"payments": {Files: []string{"payments.md"}, Extends: "backend"},
+					"orphan":   {Files: []string{"orphan.md"}, Extends: "missing"},
+					"loop":     {Extends: "loop"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    []string
+		wantErr string
+	}{
+		{name: "Rule set without a base", key: "default", want: []string{"base.md"}},
+		{name: "Base files come first", key: "payments", want: []string{"base.md", "backend.md", "payments.md"}},
+		{name: "Unknown key", key: "frontend", wantErr: "rule key 'frontend' not found"},
+		{name: "Unknown base", key: "orphan", wantErr: "rule key 'missing' extended by 'orphan' not found"},
+		{name: "Cycle", key: "loop", wantErr: "loop -> loop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.RuleFiles("claude", "local", tt.key)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Config_Templated(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Editors: map[string]EditorConfig{
+			"claude": {
+				Local: map[string]RuleSet{
+					"default":  {Files: []string{"base.md"}},
+					"base":     {Files: []string{"base.md"}, Template: true},
+					"backend":  {Files: []string{"backend.md"}, Extends: "base"},
+					"frontend": {Files: []string{"frontend.md"}, Extends: "default", Template: true},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    bool
+		wantErr bool
+	}{
+		{name: "Rule set without template", key: "default", want: false},
+		{name: "Rule set with template", key: "frontend", want: true},
+		{name: "Rule set extending a template", key: "backend", want: true},
+		{name: "Unknown key", key: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.Templated("claude", "local", tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
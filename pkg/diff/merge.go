@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Conflict markers written around the lines that couldn't be merged.
+const (
+	markerOurs   = "<<<<<<< "
+	markerBase   = "||||||| "
+	markerSep    = "=======\n"
+	markerTheirs = ">>>>>>> "
+)
+
+// Labels name the versions in conflict markers.
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs, like diff3.
+// Changes to different lines are combined; lines that both sides changed differently are written
+// between conflict markers with the base lines, and the number of such conflicts is returned.
+func Merge3(base, ours, theirs []byte, labels Labels) ([]byte, int) {
+	baseLines, ourLines, theirLines := Lines(base), Lines(ours), Lines(theirs)
+	ourMatches := match(baseLines, ourLines)
+	theirMatches := match(baseLines, theirLines)
+
+	var merged strings.Builder
+	conflicts := 0
+	i, a, b := 0, 0, 0
+	for i < len(baseLines) || a < len(ourLines) || b < len(theirLines) {
+		// Copy the lines that are unchanged on both sides
+		stable := 0
+		for i+stable < len(baseLines) && ourMatches[i+stable] == a+stable && theirMatches[i+stable] == b+stable {
+			merged.WriteString(baseLines[i+stable])
+			stable++
+		}
+		if stable > 0 {
+			i, a, b = i+stable, a+stable, b+stable
+
+			continue
+		}
+
+		// Find the next base line that both sides kept, which ends the changed chunk
+		j := i
+		for j < len(baseLines) && (ourMatches[j] < 0 || theirMatches[j] < 0) {
+			j++
+		}
+		nextA, nextB := len(ourLines), len(theirLines)
+		if j < len(baseLines) {
+			nextA, nextB = ourMatches[j], theirMatches[j]
+		}
+
+		baseChunk, ourChunk, theirChunk := baseLines[i:j], ourLines[a:nextA], theirLines[b:nextB]
+		switch {
+		case equalLines(ourChunk, baseChunk):
+			writeLines(&merged, theirChunk)
+		case equalLines(theirChunk, baseChunk), equalLines(ourChunk, theirChunk):
+			writeLines(&merged, ourChunk)
+		default:
+			conflicts++
+			writeConflict(&merged, baseChunk, ourChunk, theirChunk, labels)
+		}
+		i, a, b = j, nextA, nextB
+	}
+
+	return []byte(merged.String()), conflicts
+}
+
+// HasConflicts reports whether content still has the conflict markers that Merge3 writes with the labels.
+func HasConflicts(content []byte, labels Labels) bool {
+	ours := false
+	for _, line := range Lines(content) {
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == markerOurs+labels.Ours:
+			ours = true
+		case ours && line == markerTheirs+labels.Theirs:
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeLines writes lines to the builder.
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+}
+
+// writeConflict writes the conflicting chunks between conflict markers.
+func writeConflict(sb *strings.Builder, base, ours, theirs []string, labels Labels) {
+	sb.WriteString(markerOurs + labels.Ours + "\n")
+	writeChunk(sb, ours)
+	sb.WriteString(markerBase + labels.Base + "\n")
+	writeChunk(sb, base)
+	sb.WriteString(markerSep)
+	writeChunk(sb, theirs)
+	sb.WriteString(markerTheirs + labels.Theirs + "\n")
+}
+
+// writeChunk writes a chunk of lines, ending it with a newline so that the next marker starts a line.
+func writeChunk(sb *strings.Builder, lines []string) {
+	writeLines(sb, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Merge3(t *testing.T) {
+	t.Parallel()
+
+	labels := Labels{Ours: "current", Base: "installed", Theirs: "airules"}
+	base := "# Rules\nA\nB\nC\n"
+
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{name: "No changes", base: base, ours: base, theirs: base, want: base},
+		{name: "Only ours changed", base: base, ours: "# Rules\nA\nB mine\nC\n", theirs: base, want: "# Rules\nA\nB mine\nC\n"},
+		{name: "Only theirs changed", base: base, ours: base, theirs: "# Rules\nA\nB\nC\nD\n", want: "# Rules\nA\nB\nC\nD\n"},
+		{
+			name:   "Both changed different lines",
+			base:   base,
+			ours:   "# Rules\nA mine\nB\nC\n",
+			theirs: "# Rules\nA\nB\nC new\n",
+			want:   "# Rules\nA mine\nB\nC new\n",
+		},
+		{
+			name:   "Both inserted at different places",
+			base:   base,
+			ours:   "# Rules\nmine\nA\nB\nC\n",
+			theirs: "# Rules\nA\nB\nC\nnew\n",
+			want:   "# Rules\nmine\nA\nB\nC\nnew\n",
+		},
+		{name: "Same change on both sides", base: base, ours: "# Rules\nA\nX\nC\n", theirs: "# Rules\nA\nX\nC\n", want: "# Rules\nA\nX\nC\n"},
+		{
+			name:          "Conflicting changes",
+			base:          base,
+			ours:          "# Rules\nA\nB mine\nC\n",
+			theirs:        "# Rules\nA\nB new\nC\n",
+			want:          "# Rules\nA\n<<<<<<< current\nB mine\n||||||| installed\nB\n=======\nB new\n>>>>>>> airules\nC\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "Conflict at the end without a trailing newline",
+			base:          "A\nB",
+			ours:          "A\nmine",
+			theirs:        "A\nnew",
+			want:          "A\n<<<<<<< current\nmine\n||||||| installed\nB\n=======\nnew\n>>>>>>> airules\n",
+			wantConflicts: 1,
+		},
+		{name: "Empty base", base: "", ours: "", theirs: "A\n", want: "A\n"},
+		{
+			name:          "Both created different content",
+			base:          "",
+			ours:          "mine\n",
+			theirs:        "new\n",
+			want:          "<<<<<<< current\nmine\n||||||| installed\n=======\nnew\n>>>>>>> airules\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, conflicts := Merge3([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), labels)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantConflicts, conflicts)
+		})
+	}
+}
+
+func Test_HasConflicts(t *testing.T) {
+	t.Parallel()
+
+	labels := Labels{Ours: "current", Base: "installed", Theirs: "airules"}
+
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "No markers", content: "# Rules\nA\n", want: false},
+		{name: "Conflict", content: "A\n<<<<<<< current\nmine\n||||||| installed\nB\n=======\nnew\n>>>>>>> airules\n", want: true},
+		{name: "Markers with other labels", content: "<<<<<<< HEAD\nmine\n=======\nnew\n>>>>>>> main\n", want: false},
+		{name: "End marker only", content: "A\n>>>>>>> airules\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, HasConflicts([]byte(tt.content), labels))
+		})
+	}
+}
+
+func Test_Lines(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Lines(nil))
+	assert.Equal(t, []string{"a\n", "\n", "b"}, Lines([]byte("a\n\nb")))
+}
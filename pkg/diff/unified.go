@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// noNewline follows a line that doesn't end with a newline in a unified diff.
+const noNewline = "\\ No newline at end of file\n"
+
+// edit is a line of an edit script: ' ' kept, '-' removed, or '+' added.
+type edit struct {
+	kind byte
+	line string
+}
+
+// Unified returns the unified diff from before to after with the given number of context lines,
+// or an empty string if they're equal.
+func Unified(beforeName, afterName string, before, after []byte, context int) string {
+	edits := editScript(Lines(before), Lines(after))
+	hunks := hunkRanges(edits, context)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	// beforeLine[k] and afterLine[k] are the numbers of lines of before and after preceding edits[k]
+	beforeLine, afterLine := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	for k, e := range edits {
+		beforeLine[k+1], afterLine[k+1] = beforeLine[k], afterLine[k]
+		if e.kind != '+' {
+			beforeLine[k+1]++
+		}
+		if e.kind != '-' {
+			afterLine[k+1]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", beforeName, afterName)
+	for _, h := range hunks {
+		start, end := h[0], h[1]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(beforeLine[start], beforeLine[end]-beforeLine[start]),
+			hunkRange(afterLine[start], afterLine[end]-afterLine[start]))
+		for _, e := range edits[start:end] {
+			sb.WriteByte(e.kind)
+			sb.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				sb.WriteString("\n" + noNewline)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// editScript returns the edits that turn a into b.
+func editScript(a, b []string) []edit {
+	matches := match(a, b)
+
+	var edits []edit
+	j := 0
+	for i, line := range a {
+		if matches[i] < 0 {
+			edits = append(edits, edit{kind: '-', line: line})
+
+			continue
+		}
+		for ; j < matches[i]; j++ {
+			edits = append(edits, edit{kind: '+', line: b[j]})
+		}
+		edits = append(edits, edit{kind: ' ', line: line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{kind: '+', line: b[j]})
+	}
+
+	return edits
+}
+
+// hunkRanges returns the start and end indexes of the hunks of edits: the changes with context lines around them,
+// merging changes that are at most twice the context apart.
+func hunkRanges(edits []edit, context int) [][2]int {
+	var hunks [][2]int
+	prevEnd := 0
+	for k := 0; k < len(edits); {
+		// Skip to the next change
+		for k < len(edits) && edits[k].kind == ' ' {
+			k++
+		}
+		if k == len(edits) {
+			break
+		}
+		start := max(k-context, prevEnd)
+
+		// Extend the hunk while the following change is close enough
+		end := k
+		for {
+			for end < len(edits) && edits[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].kind == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+
+		prevEnd = min(end+context, len(edits))
+		hunks = append(hunks, [2]int{start, prevEnd})
+		k = prevEnd
+	}
+
+	return hunks
+}
+
+// hunkRange formats the line range of a hunk that covers count lines after the first skipped lines.
+func hunkRange(skipped, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", skipped)
+	case 1:
+		return fmt.Sprintf("%d", skipped+1)
+	default:
+		return fmt.Sprintf("%d,%d", skipped+1, count)
+	}
+}
@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Unified(t *testing.T) {
+	t.Parallel()
+
+	long := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		context int
+		want    string
+	}{
+		{name: "Equal", before: "A\nB\n", after: "A\nB\n", context: 3, want: ""},
+		{
+			name:    "Changed line",
+			before:  "A\nB\nC\n",
+			after:   "A\nX\nC\n",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -1,3 +1,3 @@\n A\n-B\n+X\n C\n",
+		},
+		{
+			name:    "New file",
+			before:  "",
+			after:   "A\nB\n",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+A\n+B\n",
+		},
+		{
+			name:    "Removed last line",
+			before:  "A\nB\n",
+			after:   "A\n",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -2 +1,0 @@\n-B\n",
+		},
+		{
+			name:    "Separate hunks",
+			before:  long,
+			after:   "1\nX\n3\n4\n5\n6\n7\n8\nY\n10\n",
+			context: 1,
+			want:    "--- a\n+++ b\n@@ -1,3 +1,3 @@\n 1\n-2\n+X\n 3\n@@ -8,3 +8,3 @@\n 8\n-9\n+Y\n 10\n",
+		},
+		{
+			name:    "Close changes share a hunk",
+			before:  long,
+			after:   "1\nX\n3\n4\nY\n6\n7\n8\n9\n10\n",
+			context: 1,
+			want:    "--- a\n+++ b\n@@ -1,6 +1,6 @@\n 1\n-2\n+X\n 3\n 4\n-5\n+Y\n 6\n",
+		},
+		{
+			name:    "No newline at end of file",
+			before:  "A\nB",
+			after:   "A\nB\n",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -1,2 +1,2 @@\n A\n-B\n\\ No newline at end of file\n+B\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Unified("a", "b", []byte(tt.before), []byte(tt.after), tt.context)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
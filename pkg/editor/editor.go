@@ -0,0 +1,111 @@
+// Package editor defines the interface airules uses to install rules for an editor,
+// and a registry that programs embedding airules can use to add their own editors.
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Mode is the scope of an installation.
+type Mode string
+
+const (
+	// Local installs rules into the current project.
+	Local Mode = "local"
+	// Global installs rules for the current user.
+	Global Mode = "global"
+)
+
+const (
+	// FormatText separates combined rule files with "// From <file>" lines.
+	FormatText = "text"
+	// FormatMarkdown separates combined rule files with "<!-- From <file> -->" comments.
+	FormatMarkdown = "markdown"
+	// FormatPlain separates combined rule files with blank lines only.
+	FormatPlain = "plain"
+)
+
+// Rule is a rule source file read from the templates directory.
+type Rule struct {
+	Path    string
+	Content []byte
+}
+
+// Output is a file to be written by an installation.
+type Output struct {
+	Path    string
+	Content []byte
+	// Patch, when set, derives the content from the existing file (nil if it doesn't exist) instead of Content.
+	Patch func(existing []byte) ([]byte, error)
+	// PatchID identifies the change Patch makes, as "<kind>:<argument>", so that uninstall can revert it and keep
+	// the rest of the file. Patches without an ID are uninstalled like other files.
+	PatchID string
+	// Managed writes Content into a block delimited by airules markers, keeping the rest of an existing file.
+	// It's meant for files that users also edit by hand, such as CLAUDE.md or AGENTS.md.
+	Managed bool
+}
+
+// Rules holds the rule files of a rule set and where they are installed.
+type Rules struct {
+	Mode Mode
+	// Key is the name of the rule set.
+	Key string
+	// Destination is one of the paths returned by Destinations, adjusted for nested directories.
+	Destination string
+	Files       []Rule
+	// Options holds the editor options from config.toml.
+	Options map[string]string
+}
+
+// Editor installs rule files for an AI-powered editor or agent.
+type Editor interface {
+	// Name returns the name used to select the editor, such as "cursor".
+	Name() string
+	// SupportsGlobal reports whether the editor reads global rules from a file.
+	SupportsGlobal() bool
+	// Destinations returns the files the editor reads rules from in the mode.
+	Destinations(mode Mode) ([]string, error)
+	// Render turns the rule files into the files to write for a destination.
+	Render(rules Rules) ([]Output, error)
+	// Validate reports whether the editor is usable on this system.
+	Validate() error
+}
+
+// GlobalModeExplainer is implemented by editors that explain how to set global rules when SupportsGlobal is false.
+type GlobalModeExplainer interface {
+	GlobalUnsupportedReason() string
+}
+
+// Combine combines rule files into a single file content, separating them as the format specifies.
+func Combine(files []Rule, format string) []byte {
+	var combinedContent strings.Builder
+
+	for _, file := range files {
+		// Add file content with a separator
+		if combinedContent.Len() > 0 {
+			combinedContent.WriteString("\n\n")
+		}
+		switch format {
+		case FormatMarkdown:
+			combinedContent.WriteString(fmt.Sprintf("<!-- From %s -->\n", filepath.Base(file.Path)))
+		case FormatPlain:
+		default:
+			combinedContent.WriteString(fmt.Sprintf("// From %s\n", filepath.Base(file.Path)))
+		}
+		combinedContent.Write(file.Content)
+	}
+
+	return []byte(combinedContent.String())
+}
+
+// IsValidFormat reports whether format is one of the formats understood by Combine.
+func IsValidFormat(format string) bool {
+	switch format {
+	case "", FormatText, FormatMarkdown, FormatPlain:
+		return true
+	default:
+		return false
+	}
+}
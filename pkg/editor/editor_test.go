@@ -0,0 +1,35 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Combine(t *testing.T) {
+	t.Parallel()
+
+	files := []Rule{
+		{Path: "templates/a.md", Content: []byte("rule a\n")},
+		{Path: "templates/b.md", Content: []byte("rule b\n")},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "Text format", format: FormatText, want: "// From a.md\nrule a\n\n\n// From b.md\nrule b\n"},
+		{name: "Empty format defaults to text", format: "", want: "// From a.md\nrule a\n\n\n// From b.md\nrule b\n"},
+		{name: "Markdown format", format: FormatMarkdown, want: "<!-- From a.md -->\nrule a\n\n\n<!-- From b.md -->\nrule b\n"},
+		{name: "Plain format", format: FormatPlain, want: "rule a\n\n\nrule b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, string(Combine(files, tt.format)))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package editor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Editor)
+)
+
+// Register adds an editor to the registry. It returns an error if the name is empty or already registered.
+func Register(e Editor) error {
+	name := e.Name()
+	if name == "" {
+		return fmt.Errorf("editor name is required")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("editor '%s' is already registered", name)
+	}
+	registry[name] = e
+
+	return nil
+}
+
+// MustRegister is like Register but panics if the editor can't be registered.
+func MustRegister(e Editor) {
+	if err := Register(e); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the registered editor with the name.
+func Lookup(name string) (Editor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	e, ok := registry[name]
+
+	return e, ok
+}
+
+// Names returns the sorted names of the registered editors.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,63 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEditor is a minimal Editor used by tests.
+type testEditor struct {
+	name string
+}
+
+func (e testEditor) Name() string                        { return e.name }
+func (e testEditor) SupportsGlobal() bool                { return false }
+func (e testEditor) Destinations(Mode) ([]string, error) { return []string{"RULES.md"}, nil }
+func (e testEditor) Render(Rules) ([]Output, error)      { return nil, nil }
+func (e testEditor) Validate() error                     { return nil }
+
+func Test_Register(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		editors []Editor
+		wantErr bool
+	}{
+		{name: "Register an editor", editors: []Editor{testEditor{name: "test-register"}}},
+		{name: "Register an editor without a name", editors: []Editor{testEditor{}}, wantErr: true},
+		{
+			name:    "Register the same name twice",
+			editors: []Editor{testEditor{name: "test-duplicate"}, testEditor{name: "test-duplicate"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			for _, e := range tt.editors {
+				if err = Register(e); err != nil {
+					break
+				}
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			for _, e := range tt.editors {
+				got, ok := Lookup(e.Name())
+				require.True(t, ok)
+				assert.Equal(t, e, got)
+				assert.Contains(t, Names(), e.Name())
+			}
+		})
+	}
+}
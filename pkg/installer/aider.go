@@ -0,0 +1,175 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+const (
+	// aiderConfFileName is the name of Aider's YAML config file.
+	aiderConfFileName = ".aider.conf.yml"
+	// aiderReadKey is the config key listing files Aider always reads.
+	aiderReadKey = "read"
+	// aiderReadPatch is the kind of patch that adds a file to the read list, reverted by removeAiderReadFile.
+	aiderReadPatch = "aider-read"
+)
+
+// newAiderRender returns a Render function that writes CONVENTIONS.md and adds it to the read list
+// of .aider.conf.yml, next to the conventions file for local installs and at globalConfPath for global installs.
+func newAiderRender(globalConfPath string) func(rules editor.Rules) ([]editor.Output, error) {
+	return func(rules editor.Rules) ([]editor.Output, error) {
+		// Aider resolves relative read entries from where it runs, so only global installs need an absolute path
+		confPath := filepath.Join(filepath.Dir(rules.Destination), aiderConfFileName)
+		readPath := filepath.Base(rules.Destination)
+		if rules.Mode == editor.Global {
+			confPath = globalConfPath
+			readPath = rules.Destination
+		}
+
+		return []editor.Output{
+			{Path: rules.Destination, Content: editor.Combine(rules.Files, editor.FormatMarkdown), Managed: true},
+			{
+				Path: confPath,
+				Patch: func(existing []byte) ([]byte, error) {
+					return addAiderReadFile(existing, readPath)
+				},
+				PatchID: aiderReadPatch + ":" + readPath,
+			},
+		}, nil
+	}
+}
+
+// addAiderReadFile adds path to the read list of the Aider config content, keeping other keys and comments.
+// The content is returned unchanged when the list already contains path.
+func addAiderReadFile(conf []byte, path string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(conf, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Aider config: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid Aider config: top level must be a mapping")
+	}
+
+	entry := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path}
+	read := findYAMLMappingValue(root, aiderReadKey)
+	switch {
+	case read == nil:
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: aiderReadKey},
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{entry}},
+		)
+	case read.Kind == yaml.ScalarNode:
+		if read.Value == path {
+			return conf, nil
+		}
+		existing := *read
+		*read = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{&existing, entry}}
+	case read.Kind == yaml.SequenceNode:
+		for _, item := range read.Content {
+			if item.Value == path {
+				return conf, nil
+			}
+		}
+		read.Content = append(read.Content, entry)
+	default:
+		return nil, fmt.Errorf("invalid Aider config: '%s' must be a file name or a list of file names", aiderReadKey)
+	}
+
+	return encodeAiderConfig(&doc)
+}
+
+// removeAiderReadFile removes path from the read list of the Aider config content, and the list when it's left
+// empty, keeping other keys and comments. It also reports whether the config has no keys left.
+// The content is returned unchanged when the list doesn't contain path.
+func removeAiderReadFile(conf []byte, path string) ([]byte, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(conf, &doc); err != nil {
+		return nil, false, fmt.Errorf("invalid Aider config: %w", err)
+	}
+	if doc.Kind == 0 {
+		return conf, true, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("invalid Aider config: top level must be a mapping")
+	}
+
+	read := findYAMLMappingValue(root, aiderReadKey)
+	if read == nil {
+		return conf, len(root.Content) == 0, nil
+	}
+
+	switch read.Kind {
+	case yaml.ScalarNode:
+		if read.Value != path {
+			return conf, false, nil
+		}
+		removeYAMLMappingKey(root, aiderReadKey)
+	case yaml.SequenceNode:
+		items := slices.DeleteFunc(slices.Clone(read.Content), func(item *yaml.Node) bool { return item.Value == path })
+		if len(items) == len(read.Content) {
+			return conf, false, nil
+		}
+		read.Content = items
+		if len(items) == 0 {
+			removeYAMLMappingKey(root, aiderReadKey)
+		}
+	default:
+		return nil, false, fmt.Errorf("invalid Aider config: '%s' must be a file name or a list of file names", aiderReadKey)
+	}
+
+	content, err := encodeAiderConfig(&doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return content, len(root.Content) == 0, nil
+}
+
+// encodeAiderConfig encodes the Aider config document indented with two spaces.
+func encodeAiderConfig(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// findYAMLMappingValue returns the value node for key in a mapping node, or nil if the key is absent.
+func findYAMLMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// removeYAMLMappingKey removes key and its value from a mapping node.
+func removeYAMLMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = slices.Delete(mapping.Content, i, i+2)
+
+			return
+		}
+	}
+}
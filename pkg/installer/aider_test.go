@@ -0,0 +1,170 @@
+package installer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newAiderRender(t *testing.T) {
+	t.Parallel()
+
+	rules := []editor.Rule{{Path: "templates/aider/local/CONVENTIONS.md", Content: []byte("rule\n")}}
+	globalConfPath := filepath.Join("home", ".aider.conf.yml")
+
+	tests := []struct {
+		name         string
+		mode         string
+		destPath     string
+		wantConfPath string
+		wantConf     string
+	}{
+		{
+			name:         "Local install reads the conventions file relative to the project",
+			mode:         "local",
+			destPath:     "CONVENTIONS.md",
+			wantConfPath: ".aider.conf.yml",
+			wantConf:     "read:\n  - CONVENTIONS.md\n",
+		},
+		{
+			name:         "Global install reads the conventions file by its full path",
+			mode:         "global",
+			destPath:     filepath.Join("home", ".aider", "CONVENTIONS.md"),
+			wantConfPath: globalConfPath,
+			wantConf:     "read:\n  - " + filepath.Join("home", ".aider", "CONVENTIONS.md") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newAiderRender(globalConfPath)(editor.Rules{Mode: editor.Mode(tt.mode), Key: "default", Destination: tt.destPath, Files: rules})
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+
+			assert.Equal(t, tt.destPath, got[0].Path)
+			assert.Equal(t, "<!-- From CONVENTIONS.md -->\nrule\n", string(got[0].Content))
+
+			assert.Equal(t, tt.wantConfPath, got[1].Path)
+			require.NotNil(t, got[1].Patch)
+			conf, err := got[1].Patch(nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantConf, string(conf))
+		})
+	}
+}
+
+func Test_addAiderReadFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		conf    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Empty config",
+			conf: "",
+			want: "read:\n  - CONVENTIONS.md\n",
+		},
+		{
+			name: "Keep other keys and comments",
+			conf: "# Aider settings\nmodel: sonnet # preferred model\n",
+			want: "# Aider settings\nmodel: sonnet # preferred model\nread:\n  - CONVENTIONS.md\n",
+		},
+		{
+			name: "Append to an existing list",
+			conf: "read:\n  - docs/STYLE.md\n",
+			want: "read:\n  - docs/STYLE.md\n  - CONVENTIONS.md\n",
+		},
+		{
+			name: "Convert a single file name to a list",
+			conf: "read: docs/STYLE.md\n",
+			want: "read:\n  - docs/STYLE.md\n  - CONVENTIONS.md\n",
+		},
+		{
+			name: "Already listed",
+			conf: "read: [CONVENTIONS.md]  # keep as is\n",
+			want: "read: [CONVENTIONS.md]  # keep as is\n",
+		},
+		{name: "Top level is not a mapping", conf: "- CONVENTIONS.md\n", wantErr: true},
+		{name: "Read is a mapping", conf: "read:\n  file: CONVENTIONS.md\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := addAiderReadFile([]byte(tt.conf), "CONVENTIONS.md")
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_removeAiderReadFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		conf      string
+		want      string
+		wantEmpty bool
+		wantErr   bool
+	}{
+		{
+			name: "Keep other entries, keys and comments",
+			conf: "# Aider settings\nmodel: sonnet # preferred model\nread:\n  - docs/STYLE.md\n  - CONVENTIONS.md\n",
+			want: "# Aider settings\nmodel: sonnet # preferred model\nread:\n  - docs/STYLE.md\n",
+		},
+		{
+			name: "Remove the list when it's left empty",
+			conf: "model: sonnet\nread:\n  - CONVENTIONS.md\nauto-commits: false\n",
+			want: "model: sonnet\nauto-commits: false\n",
+		},
+		{
+			name: "Remove a single file name",
+			conf: "read: CONVENTIONS.md\nmodel: sonnet\n",
+			want: "model: sonnet\n",
+		},
+		{
+			name:      "No keys left",
+			conf:      "read:\n  - CONVENTIONS.md\n",
+			want:      "{}\n",
+			wantEmpty: true,
+		},
+		{
+			name: "Not listed",
+			conf: "read: [docs/STYLE.md]  # keep as is\n",
+			want: "read: [docs/STYLE.md]  # keep as is\n",
+		},
+		{name: "Empty config", conf: "", want: "", wantEmpty: true},
+		{name: "Top level is not a mapping", conf: "- CONVENTIONS.md\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, empty, err := removeAiderReadFile([]byte(tt.conf), "CONVENTIONS.md")
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantEmpty, empty)
+		})
+	}
+}
@@ -0,0 +1,521 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+const (
+	// backupSuffix separates the name of a backed-up file from the time of the backup.
+	backupSuffix = ".backup_"
+	// backupTimeFormat is the format of the time in backup names, which sorts in time order.
+	backupTimeFormat = "20060102_150405"
+	// backupRootFileName is the file in each project's backup directory that holds the project's path.
+	backupRootFileName = "root"
+)
+
+// Backup is a backup of a file that an install replaced.
+type Backup struct {
+	// Path is the file that was backed up; files in the project are relative to it.
+	Path string
+	// BackupPath is where the backup is stored.
+	BackupPath string
+	Time       time.Time
+}
+
+// useBackupDir stores backups in the backups directory of the config directory, grouped by the working directory.
+func (inst *installation) useBackupDir() error {
+	backupDir, err := config.GetBackupsDir()
+	if err != nil {
+		return fmt.Errorf("failed to get backups directory: %w", err)
+	}
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	inst.backupDir, inst.projectDir = backupDir, projectDir
+
+	return nil
+}
+
+// newBackupInstallation returns an installation for managing the backups of the project in the working directory.
+func newBackupInstallation() (*installation, error) {
+	inst := newInstallation(NewOsFS(), "")
+	if err := inst.useConfigDirs(); err != nil {
+		return nil, err
+	}
+
+	return inst, nil
+}
+
+// backupRoot returns the directory that the file is backed up relative to: the project directory for files in it,
+// or the root of the file's volume, and the file's path relative to it.
+func (inst *installation) backupRoot(filePath string) (root, rel string, err error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve '%s': %w", filePath, err)
+	}
+
+	if inst.projectDir != "" {
+		if rel, err := filepath.Rel(inst.projectDir, absPath); err == nil && filepath.IsLocal(rel) {
+			return inst.projectDir, rel, nil
+		}
+	}
+
+	root = filepath.VolumeName(absPath) + string(filepath.Separator)
+	rel, err = filepath.Rel(root, absPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve '%s': %w", filePath, err)
+	}
+
+	return root, rel, nil
+}
+
+// backupBucket returns the directory that holds the backups of the files under root.
+func (inst *installation) backupBucket(root string) string {
+	sum := sha256.Sum256([]byte(root))
+
+	return filepath.Join(inst.backupDir, hex.EncodeToString(sum[:8]))
+}
+
+// backupFileDir returns the directory that holds the backups of the file.
+func (inst *installation) backupFileDir(filePath string) (string, error) {
+	if inst.backupDir == "" {
+		return filepath.Dir(filePath), nil
+	}
+
+	root, rel, err := inst.backupRoot(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(inst.backupBucket(root), filepath.Dir(rel)), nil
+}
+
+// createBackup makes a backup of an existing file. Backups are written as part of the installation's transaction,
+// so an install that's rolled back leaves none.
+func (inst *installation) createBackup(filePath string) error {
+	// Check if the file exists
+	info, err := inst.fs.Stat(filePath)
+	if os.IsNotExist(err) {
+		// No backup needed if the file doesn't exist
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check file existence: %w", err)
+	}
+
+	content, err := inst.fs.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file to back up: %w", err)
+	}
+
+	if inst.skipUnchangedBackups {
+		unchanged, err := inst.sameAsLatestBackup(filePath, content)
+		if err != nil || unchanged {
+			return err
+		}
+	}
+
+	dir, err := inst.backupFileDir(filePath)
+	if err != nil {
+		return err
+	}
+	if err := inst.recordBackupRoot(filePath); err != nil {
+		return err
+	}
+
+	// Generate backup filename (original filename + .backup_YYYYMMDD_hhmmss),
+	// moving to the next second when a backup was already made in this one
+	backupTime := time.Now()
+	backupPath := filepath.Join(dir, filepath.Base(filePath)+backupSuffix+backupTime.Format(backupTimeFormat))
+	for {
+		_, err := inst.fs.Stat(backupPath)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check backup file existence: %w", err)
+		}
+		backupTime = backupTime.Add(time.Second)
+		backupPath = filepath.Join(dir, filepath.Base(filePath)+backupSuffix+backupTime.Format(backupTimeFormat))
+	}
+
+	if err := inst.mkdirAll(dir); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := inst.writeFile(backupPath, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	inst.afterCommit(func() {
+		fmt.Fprintf(inst.out, "Created backup of existing file at: %s\n", backupPath)
+	})
+
+	return nil
+}
+
+// backupDestination makes a backup of an existing destination before it's replaced, and remembers the first backup
+// of each destination, which holds its content from before the installation.
+func (inst *installation) backupDestination(path string) error {
+	if _, err := inst.fs.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := inst.createBackup(path); err != nil {
+		return err
+	}
+
+	path = filepath.Clean(path)
+	if _, ok := inst.backups[path]; ok {
+		return nil
+	}
+
+	// The most recent backup is the one just made, or the one with the same content when it was skipped
+	latest, err := inst.latestBackup(path)
+	if err != nil || latest == "" {
+		return err
+	}
+	if inst.backups == nil {
+		inst.backups = make(map[string]string)
+	}
+	inst.backups[path] = latest
+
+	return nil
+}
+
+// recordBackupRoot writes the path of the directory the file is backed up relative to into its backup directory,
+// so that backups can be listed and restored to their original paths.
+func (inst *installation) recordBackupRoot(filePath string) error {
+	if inst.backupDir == "" {
+		return nil
+	}
+
+	root, _, err := inst.backupRoot(filePath)
+	if err != nil {
+		return err
+	}
+
+	bucket := inst.backupBucket(root)
+	rootPath := filepath.Join(bucket, backupRootFileName)
+	if existing, err := inst.fs.ReadFile(rootPath); err == nil && string(existing) == root {
+		return nil
+	}
+
+	if err := inst.mkdirAll(bucket); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := inst.writeFile(rootPath, []byte(root), 0o644); err != nil {
+		return fmt.Errorf("failed to record backup root: %w", err)
+	}
+
+	return nil
+}
+
+// sameAsLatestBackup reports whether content is the same as the file's most recent backup.
+func (inst *installation) sameAsLatestBackup(filePath string, content []byte) (bool, error) {
+	latest, err := inst.latestBackup(filePath)
+	if err != nil || latest == "" {
+		return false, err
+	}
+
+	backup, err := inst.fs.ReadFile(latest)
+	if err != nil {
+		return false, fmt.Errorf("failed to read backup '%s': %w", latest, err)
+	}
+
+	if !bytes.Equal(backup, content) {
+		return false, nil
+	}
+	fmt.Fprintf(inst.out, "Skipped backup of %s: it's unchanged since %s\n", filePath, latest)
+
+	return true, nil
+}
+
+// fileBackups returns the backups of the file, oldest first. Backups made next to the file by earlier versions
+// of airules are included.
+func (inst *installation) fileBackups(filePath string) ([]Backup, error) {
+	dirs := []string{filepath.Dir(filePath)}
+	if inst.backupDir != "" {
+		dir, err := inst.backupFileDir(filePath)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	var backups []Backup
+	for _, dir := range dirs {
+		entries, err := inst.fs.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups of '%s': %w", filePath, err)
+		}
+
+		for _, entry := range entries {
+			name, backupTime, ok := parseBackupName(entry.Name())
+			if !entry.IsDir() && ok && name == filepath.Base(filePath) {
+				backups = append(backups, Backup{Path: filePath, BackupPath: filepath.Join(dir, entry.Name()), Time: backupTime})
+			}
+		}
+	}
+	sortBackups(backups)
+
+	return backups, nil
+}
+
+// latestBackup returns the most recent backup of the file, or an empty string if there is none.
+func (inst *installation) latestBackup(filePath string) (string, error) {
+	backups, err := inst.fileBackups(filePath)
+	if err != nil || len(backups) == 0 {
+		return "", err
+	}
+
+	return backups[len(backups)-1].BackupPath, nil
+}
+
+// parseBackupName returns the name of the backed-up file and the time of the backup from a backup name.
+func parseBackupName(name string) (string, time.Time, bool) {
+	i := strings.LastIndex(name, backupSuffix)
+	if i <= 0 {
+		return "", time.Time{}, false
+	}
+
+	backupTime, err := time.ParseInLocation(backupTimeFormat, name[i+len(backupSuffix):], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return name[:i], backupTime, true
+}
+
+// sortBackups sorts backups by path and then by time, oldest first.
+func sortBackups(backups []Backup) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].Path != backups[j].Path {
+			return backups[i].Path < backups[j].Path
+		}
+
+		return backups[i].Time.Before(backups[j].Time)
+	})
+}
+
+// ListBackups returns the backups of the files in the project in the working directory and of global files,
+// sorted by path and then by time.
+func ListBackups() ([]Backup, error) {
+	inst, err := newBackupInstallation()
+	if err != nil {
+		return nil, err
+	}
+
+	return inst.listBackups()
+}
+
+// listBackups returns the backups stored for the project and for files outside any project.
+func (inst *installation) listBackups() ([]Backup, error) {
+	entries, err := inst.fs.ReadDir(inst.backupDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		bucket := filepath.Join(inst.backupDir, entry.Name())
+		root, err := inst.fs.ReadFile(filepath.Join(bucket, backupRootFileName))
+		if err != nil {
+			continue
+		}
+
+		// Backups of other projects are left out
+		rootPath := string(root)
+		if rootPath != inst.projectDir && rootPath != filepath.VolumeName(rootPath)+string(filepath.Separator) {
+			continue
+		}
+
+		found, err := inst.walkBackups(bucket, rootPath, "")
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, found...)
+	}
+	sortBackups(backups)
+
+	return backups, nil
+}
+
+// walkBackups returns the backups under the directory rel of a project's backup directory.
+func (inst *installation) walkBackups(bucket, root, rel string) ([]Backup, error) {
+	entries, err := inst.fs.ReadDir(filepath.Join(bucket, rel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			found, err := inst.walkBackups(bucket, root, filepath.Join(rel, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			backups = append(backups, found...)
+
+			continue
+		}
+
+		name, backupTime, ok := parseBackupName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(rel, name)
+		if root != inst.projectDir {
+			path = filepath.Join(root, path)
+		}
+		backups = append(backups, Backup{Path: path, BackupPath: filepath.Join(bucket, rel, entry.Name()), Time: backupTime})
+	}
+
+	return backups, nil
+}
+
+// RestoreBackup restores the file from its backup made at the given time, formatted like the time in backup names,
+// or from its most recent backup when at is empty. The current file is backed up first.
+func RestoreBackup(path, at string) (Backup, error) {
+	inst, err := newBackupInstallation()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	return inst.restoreBackup(path, at)
+}
+
+// restoreBackup restores the file from one of its backups.
+func (inst *installation) restoreBackup(path, at string) (Backup, error) {
+	backups, err := inst.fileBackups(path)
+	if err != nil {
+		return Backup{}, err
+	}
+	if len(backups) == 0 {
+		return Backup{}, fmt.Errorf("no backups of '%s' found", path)
+	}
+
+	backup := backups[len(backups)-1]
+	if at != "" {
+		i := slices.IndexFunc(backups, func(b Backup) bool { return b.Time.Format(backupTimeFormat) == at })
+		if i < 0 {
+			return Backup{}, fmt.Errorf("no backup of '%s' made at %s found", path, at)
+		}
+		backup = backups[i]
+	}
+
+	content, err := inst.fs.ReadFile(backup.BackupPath)
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to read backup '%s': %w", backup.BackupPath, err)
+	}
+
+	if err := inst.createBackup(path); err != nil {
+		return Backup{}, err
+	}
+	if err := inst.mkdirAll(filepath.Dir(path)); err != nil {
+		return Backup{}, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := inst.writeFile(path, content, 0o644); err != nil {
+		return Backup{}, fmt.Errorf("failed to restore '%s': %w", path, err)
+	}
+
+	return backup, nil
+}
+
+// PruneOptions select the backups to remove. A backup is removed when it's older than the newest Keep backups of
+// its file and, if OlderThan is set, older than OlderThan.
+type PruneOptions struct {
+	Keep      int
+	OlderThan time.Duration
+}
+
+// PruneBackups removes old backups of the files in the project in the working directory and of global files,
+// and returns the backups it removed.
+func PruneBackups(opts PruneOptions) ([]Backup, error) {
+	inst, err := newBackupInstallation()
+	if err != nil {
+		return nil, err
+	}
+
+	return inst.pruneBackups(opts, time.Now())
+}
+
+// pruneBackups removes the backups selected by the options at the time now.
+// Backups that a manifest records as the original of an installed file are kept, since uninstall restores them.
+func (inst *installation) pruneBackups(opts PruneOptions, now time.Time) ([]Backup, error) {
+	backups, err := inst.listBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	originals, err := inst.manifestOriginals()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Backup
+	for i, backup := range backups {
+		// Backups are sorted by path and then by time, so the newer backups of the file follow this one
+		newer := 0
+		for j := i + 1; j < len(backups) && backups[j].Path == backup.Path; j++ {
+			newer++
+		}
+		if newer < opts.Keep || (opts.OlderThan > 0 && now.Sub(backup.Time) <= opts.OlderThan) {
+			continue
+		}
+		if originals[filepath.Clean(backup.BackupPath)] {
+			continue
+		}
+
+		if err := inst.fs.Remove(backup.BackupPath); err != nil {
+			return removed, fmt.Errorf("failed to remove backup '%s': %w", backup.BackupPath, err)
+		}
+		removed = append(removed, backup)
+	}
+
+	return removed, nil
+}
+
+// manifestOriginals returns the backups that the local and global manifests record as originals of installed files.
+func (inst *installation) manifestOriginals() (map[string]bool, error) {
+	originals := make(map[string]bool)
+	for _, mode := range []editor.Mode{editor.Local, editor.Global} {
+		m, err := inst.readManifest(inst.manifestPath(mode))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range m.Files {
+			if file.Original != "" {
+				originals[filepath.Clean(inst.resolveRecordedPath(file.Original))] = true
+			}
+		}
+	}
+
+	return originals, nil
+}
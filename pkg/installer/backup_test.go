@@ -0,0 +1,212 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashiiiii/airules/pkg/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBackupTestInstallation returns an installation that keeps backups of /project in /backups.
+func newBackupTestInstallation(fs *memFS) *installation {
+	inst := newInstallation(fs, "")
+	inst.out = io.Discard
+	inst.backupDir, inst.projectDir = "/backups", "/project"
+
+	return inst
+}
+
+func Test_installation_createBackup(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(map[string]string{
+		"/project/.cursor/rules/project_rules.mdc": "project",
+		"/home/.claude/CLAUDE.md":                  "global",
+	})
+	inst := newBackupTestInstallation(fs)
+
+	require.NoError(t, inst.createBackup("/project/.cursor/rules/project_rules.mdc"))
+	require.NoError(t, inst.createBackup("/project/.cursor/rules/project_rules.mdc"))
+	require.NoError(t, inst.createBackup("/home/.claude/CLAUDE.md"))
+
+	projectBucket, globalBucket := inst.backupBucket("/project"), inst.backupBucket("/")
+	assert.Equal(t, "/project", string(fs.files[filepath.Join(projectBucket, backupRootFileName)]))
+	assert.Equal(t, "/", string(fs.files[filepath.Join(globalBucket, backupRootFileName)]))
+
+	// Backups made in the same second get distinct names
+	projectBackups, err := inst.fileBackups("/project/.cursor/rules/project_rules.mdc")
+	require.NoError(t, err)
+	require.Len(t, projectBackups, 2)
+	for _, backup := range projectBackups {
+		assert.Equal(t, filepath.Join(projectBucket, ".cursor/rules"), filepath.Dir(backup.BackupPath))
+		assert.Equal(t, "project", string(fs.files[backup.BackupPath]))
+	}
+
+	globalBackups, err := inst.fileBackups("/home/.claude/CLAUDE.md")
+	require.NoError(t, err)
+	require.Len(t, globalBackups, 1)
+	assert.Equal(t, filepath.Join(globalBucket, "home/.claude"), filepath.Dir(globalBackups[0].BackupPath))
+
+	// Nothing is left next to the files
+	assert.Empty(t, fs.backups("/project/.cursor/rules/project_rules.mdc"))
+}
+
+func Test_installation_createBackup_skipUnchanged(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(map[string]string{"/project/CLAUDE.md": "rules"})
+	inst := newBackupTestInstallation(fs)
+	inst.skipUnchangedBackups = true
+
+	require.NoError(t, inst.createBackup("/project/CLAUDE.md"))
+	require.NoError(t, inst.createBackup("/project/CLAUDE.md"))
+	require.NoError(t, fs.WriteFile("/project/CLAUDE.md", []byte("changed"), 0o644))
+	require.NoError(t, inst.createBackup("/project/CLAUDE.md"))
+
+	backups, err := inst.fileBackups("/project/CLAUDE.md")
+	require.NoError(t, err)
+	assert.Len(t, backups, 2)
+}
+
+func Test_installation_listBackups(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(nil)
+	inst := newBackupTestInstallation(fs)
+	other := newBackupTestInstallation(fs)
+	other.projectDir = "/other"
+
+	for path, content := range map[string]string{
+		"/project/CLAUDE.md":      "project",
+		"/home/.claude/CLAUDE.md": "global",
+		"/other/CLAUDE.md":        "other",
+	} {
+		require.NoError(t, fs.WriteFile(path, []byte(content), 0o644))
+	}
+	require.NoError(t, inst.createBackup("/project/CLAUDE.md"))
+	require.NoError(t, inst.createBackup("/home/.claude/CLAUDE.md"))
+	require.NoError(t, other.createBackup("/other/CLAUDE.md"))
+
+	backups, err := inst.listBackups()
+	require.NoError(t, err)
+
+	var paths []string
+	for _, backup := range backups {
+		paths = append(paths, backup.Path)
+	}
+	assert.Equal(t, []string{"/home/.claude/CLAUDE.md", "CLAUDE.md"}, paths)
+}
+
+func Test_installation_restoreBackup(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(map[string]string{"/project/CLAUDE.md": "current"})
+	inst := newBackupTestInstallation(fs)
+	bucket := inst.backupBucket("/project")
+	fs.files[filepath.Join(bucket, "CLAUDE.md.backup_20240101_120000")] = []byte("older")
+	fs.files[filepath.Join(bucket, "CLAUDE.md.backup_20240102_120000")] = []byte("newer")
+
+	backup, err := inst.restoreBackup("/project/CLAUDE.md", "20240101_120000")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(bucket, "CLAUDE.md.backup_20240101_120000"), backup.BackupPath)
+	assert.Equal(t, "older", string(fs.files["/project/CLAUDE.md"]))
+
+	// The replaced content was backed up and is now the most recent backup
+	latest, err := inst.latestBackup("/project/CLAUDE.md")
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(fs.files[latest]))
+
+	_, err = inst.restoreBackup("/project/CLAUDE.md", "20230101_000000")
+	require.Error(t, err)
+
+	_, err = inst.restoreBackup("/project/AGENTS.md", "")
+	require.Error(t, err)
+}
+
+func Test_installation_pruneBackups(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 2, 1, 12, 0, 0, 0, time.Local)
+	names := []string{
+		"CLAUDE.md.backup_20231201_120000",
+		"CLAUDE.md.backup_20240115_120000",
+		"CLAUDE.md.backup_20240130_120000",
+		"AGENTS.md.backup_20231201_120000",
+	}
+
+	tests := []struct {
+		name        string
+		opts        PruneOptions
+		original    string
+		wantRemoved []string
+	}{
+		{
+			name:        "Keep the newest backups",
+			opts:        PruneOptions{Keep: 1},
+			wantRemoved: []string{"CLAUDE.md.backup_20231201_120000", "CLAUDE.md.backup_20240115_120000"},
+		},
+		{
+			name:        "Remove old backups",
+			opts:        PruneOptions{OlderThan: 30 * 24 * time.Hour},
+			wantRemoved: []string{"AGENTS.md.backup_20231201_120000", "CLAUDE.md.backup_20231201_120000"},
+		},
+		{
+			name:        "Remove old backups but keep the newest",
+			opts:        PruneOptions{Keep: 1, OlderThan: 7 * 24 * time.Hour},
+			wantRemoved: []string{"CLAUDE.md.backup_20231201_120000", "CLAUDE.md.backup_20240115_120000"},
+		},
+		{
+			name:        "Keep the backup recorded as the original of an installed file",
+			opts:        PruneOptions{Keep: 1},
+			original:    "CLAUDE.md.backup_20231201_120000",
+			wantRemoved: []string{"CLAUDE.md.backup_20240115_120000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newMemFS(nil)
+			inst := newBackupTestInstallation(fs)
+			bucket := inst.backupBucket("/project")
+			fs.files[filepath.Join(bucket, backupRootFileName)] = []byte("/project")
+			for _, name := range names {
+				fs.files[filepath.Join(bucket, name)] = []byte(name)
+			}
+			if tt.original != "" {
+				fs.files[manifest.LocalFileName] = []byte(fmt.Sprintf("version = 1\n\n[[file]]\npath = \"CLAUDE.md\"\noriginal = %q\n",
+					filepath.ToSlash(filepath.Join(bucket, tt.original))))
+			}
+
+			removed, err := inst.pruneBackups(tt.opts, now)
+			require.NoError(t, err)
+
+			var got []string
+			for _, backup := range removed {
+				got = append(got, filepath.Base(backup.BackupPath))
+				assert.NotContains(t, fs.files, backup.BackupPath)
+			}
+			assert.Equal(t, tt.wantRemoved, got)
+		})
+	}
+}
+
+func Test_parseBackupName(t *testing.T) {
+	t.Parallel()
+
+	name, backupTime, ok := parseBackupName("CLAUDE.md.backup_20240102_150405")
+	require.True(t, ok)
+	assert.Equal(t, "CLAUDE.md", name)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local), backupTime)
+
+	_, _, ok = parseBackupName("CLAUDE.md")
+	assert.False(t, ok)
+	_, _, ok = parseBackupName("CLAUDE.md.backup_latest")
+	assert.False(t, ok)
+}
@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+const (
+	// copilotInstructionsDir is the directory of Copilot's path-scoped instruction files.
+	copilotInstructionsDir = "instructions"
+	// copilotInstructionsSuffix is the file name suffix Copilot requires for path-scoped instruction files.
+	copilotInstructionsSuffix = ".instructions.md"
+)
+
+// renderCopilot splits rule files into the repository-wide instructions file and path-scoped instruction files.
+// Rule files whose front matter declares applyTo are written as .github/instructions/<name>.instructions.md,
+// all others are combined into .github/copilot-instructions.md.
+func renderCopilot(rules editor.Rules) ([]editor.Output, error) {
+	var repoRules []editor.Rule
+	var outputs []editor.Output
+
+	scopedDir := filepath.Join(filepath.Dir(rules.Destination), copilotInstructionsDir)
+	for _, rule := range rules.Files {
+		frontMatter, ok := parseFrontMatter(rule.Content)
+		if !ok || frontMatter["applyTo"] == "" {
+			repoRules = append(repoRules, rule)
+
+			continue
+		}
+
+		outputs = append(outputs, editor.Output{
+			Path:    filepath.Join(scopedDir, copilotInstructionsName(rule.Path)),
+			Content: rule.Content,
+		})
+	}
+
+	if len(repoRules) > 0 {
+		combined := editor.Output{Path: rules.Destination, Content: editor.Combine(repoRules, editor.FormatMarkdown), Managed: true}
+		outputs = append([]editor.Output{combined}, outputs...)
+	}
+
+	return outputs, nil
+}
+
+// copilotInstructionsName returns the instruction file name for a rule file path.
+func copilotInstructionsName(path string) string {
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, copilotInstructionsSuffix) {
+		return name
+	}
+
+	return strings.TrimSuffix(name, filepath.Ext(name)) + copilotInstructionsSuffix
+}
+
+// parseFrontMatter parses the "key: value" lines of a YAML front matter block at the start of content.
+// It reports false when content has no front matter.
+func parseFrontMatter(content []byte) (map[string]string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "---" {
+			return values, true
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	return nil, false
+}
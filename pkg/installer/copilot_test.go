@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_renderCopilot(t *testing.T) {
+	t.Parallel()
+
+	repoRule := editor.Rule{Path: "templates/copilot/local/copilot-instructions.md", Content: []byte("# Rules\n")}
+	goRule := editor.Rule{Path: "templates/copilot/local/go.md", Content: []byte("---\napplyTo: \"**/*.go\"\n---\nUse gofmt.\n")}
+	testRule := editor.Rule{
+		Path:    "templates/copilot/local/tests.instructions.md",
+		Content: []byte("---\napplyTo: '**/*_test.go'\n---\nUse testify.\n"),
+	}
+	destPath := filepath.Join(".github", "copilot-instructions.md")
+
+	tests := []struct {
+		name  string
+		rules []editor.Rule
+		want  []editor.Output
+	}{
+		{
+			name:  "Repository-wide rules only",
+			rules: []editor.Rule{repoRule},
+			want:  []editor.Output{{Path: destPath, Content: []byte("<!-- From copilot-instructions.md -->\n# Rules\n"), Managed: true}},
+		},
+		{
+			name:  "Repository-wide and scoped rules",
+			rules: []editor.Rule{goRule, repoRule, testRule},
+			want: []editor.Output{
+				{Path: destPath, Content: []byte("<!-- From copilot-instructions.md -->\n# Rules\n"), Managed: true},
+				{Path: filepath.Join(".github", "instructions", "go.instructions.md"), Content: goRule.Content},
+				{Path: filepath.Join(".github", "instructions", "tests.instructions.md"), Content: testRule.Content},
+			},
+		},
+		{
+			name:  "Scoped rules only",
+			rules: []editor.Rule{goRule},
+			want:  []editor.Output{{Path: filepath.Join(".github", "instructions", "go.instructions.md"), Content: goRule.Content}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderCopilot(editor.Rules{Mode: editor.Local, Key: "default", Destination: destPath, Files: tt.rules})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_parseFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantOK  bool
+	}{
+		{
+			name:    "Front matter with quoted values",
+			content: "---\napplyTo: \"**/*.ts\"\ndescription: TypeScript\n---\nbody\n",
+			want:    map[string]string{"applyTo": "**/*.ts", "description": "TypeScript"},
+			wantOK:  true,
+		},
+		{name: "No front matter", content: "# Title\n", wantOK: false},
+		{name: "Unterminated front matter", content: "---\napplyTo: x\n", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseFrontMatter([]byte(tt.content))
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
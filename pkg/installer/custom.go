@@ -0,0 +1,69 @@
+package installer
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/mitchellh/go-homedir"
+)
+
+// defaultOS is the global_path entry used when a custom editor has none for the current OS.
+const defaultOS = "default"
+
+// checkCustomEditorConflict returns an error if config.toml defines a custom editor with a registered editor's name.
+func checkCustomEditorConflict(name string) error {
+	if _, ok := editor.Lookup(name); !ok {
+		return nil
+	}
+
+	definitions, err := config.GetCustomEditors()
+	if err != nil {
+		return fmt.Errorf("failed to load custom editors from config.toml: %w", err)
+	}
+
+	for _, definition := range definitions {
+		if definition.Name == name {
+			return fmt.Errorf("custom editor '%s' in config.toml conflicts with the built-in editor; rename it", name)
+		}
+	}
+
+	return nil
+}
+
+// newCustomEditorConfig builds an editor configuration from a custom editor definition.
+func newCustomEditorConfig(definition config.EditorDefinition) (EditorConfig, error) {
+	editorConfig := EditorConfig{
+		LocalPath:       definition.LocalPath,
+		LocalFileName:   definition.FileName,
+		GlobalFileName:  definition.FileName,
+		GlobalSupported: definition.GlobalSupported,
+		Format:          definition.Format,
+		ManagedBlock:    definition.ManagedBlock,
+	}
+	if editorConfig.LocalPath == "" {
+		editorConfig.LocalPath = "."
+	}
+	if definition.GlobalFileName != "" {
+		editorConfig.GlobalFileName = definition.GlobalFileName
+	}
+
+	if definition.GlobalSupported {
+		globalPath, ok := definition.GlobalPath[runtime.GOOS]
+		if !ok {
+			globalPath, ok = definition.GlobalPath[defaultOS]
+		}
+		if !ok {
+			return EditorConfig{}, fmt.Errorf("no global path for editor '%s' on OS: %s", definition.Name, runtime.GOOS)
+		}
+
+		expanded, err := homedir.Expand(globalPath)
+		if err != nil {
+			return EditorConfig{}, fmt.Errorf("failed to expand global path for editor '%s': %w", definition.Name, err)
+		}
+		editorConfig.GlobalPath = expanded
+	}
+
+	return editorConfig, nil
+}
@@ -0,0 +1,94 @@
+package installer
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newCustomEditorConfig(t *testing.T) {
+	t.Parallel()
+
+	globalDir := filepath.Join(string(filepath.Separator)+"opt", "internal")
+
+	tests := []struct {
+		name       string
+		definition config.EditorDefinition
+		want       EditorConfig
+		wantErr    bool
+	}{
+		{
+			name:       "Local only editor",
+			definition: config.EditorDefinition{Name: "internal", LocalPath: ".internal", FileName: "rules.md"},
+			want:       EditorConfig{LocalPath: ".internal", LocalFileName: "rules.md", GlobalFileName: "rules.md"},
+		},
+		{
+			name:       "Local path defaults to the project root",
+			definition: config.EditorDefinition{Name: "internal", FileName: "RULES.md", Format: editor.FormatMarkdown},
+			want:       EditorConfig{LocalPath: ".", LocalFileName: "RULES.md", GlobalFileName: "RULES.md", Format: editor.FormatMarkdown},
+		},
+		{
+			name: "Global path for the current OS",
+			definition: config.EditorDefinition{
+				Name:            "internal",
+				FileName:        "rules.md",
+				GlobalFileName:  "global.md",
+				GlobalPath:      map[string]string{runtime.GOOS: globalDir, "default": "unused"},
+				GlobalSupported: true,
+			},
+			want: EditorConfig{
+				LocalPath:       ".",
+				GlobalPath:      globalDir,
+				LocalFileName:   "rules.md",
+				GlobalFileName:  "global.md",
+				GlobalSupported: true,
+			},
+		},
+		{
+			name: "Default global path",
+			definition: config.EditorDefinition{
+				Name:            "internal",
+				FileName:        "rules.md",
+				GlobalPath:      map[string]string{"default": globalDir},
+				GlobalSupported: true,
+			},
+			want: EditorConfig{
+				LocalPath:       ".",
+				GlobalPath:      globalDir,
+				LocalFileName:   "rules.md",
+				GlobalFileName:  "rules.md",
+				GlobalSupported: true,
+			},
+		},
+		{
+			name: "No global path for the current OS",
+			definition: config.EditorDefinition{
+				Name:            "internal",
+				FileName:        "rules.md",
+				GlobalPath:      map[string]string{"plan9": globalDir},
+				GlobalSupported: true,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newCustomEditorConfig(tt.definition)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+// Change is a destination file that installing rules would write.
+type Change struct {
+	Path string
+	Mode editor.Mode
+	// Exists reports whether the file exists now.
+	Exists bool
+	// Old is the current content of the file, and New is the content that would be written.
+	Old []byte
+	New []byte
+	// Conflict reports whether New has conflict markers from merging local edits, including unresolved ones.
+	Conflict bool
+}
+
+// Changed reports whether installing would create or modify the file.
+func (c Change) Changed() bool {
+	return !c.Exists || !bytes.Equal(c.Old, c.New)
+}
+
+// Plan returns the files that installing rules for the editor with the given options would write,
+// without changing anything on disk.
+func Plan(name string, installType InstallType, opts Options) ([]Change, error) {
+	base := NewOsFS()
+	inst := newInstallation(newDryRunFS(base), "")
+	inst.out = io.Discard
+	if err := inst.install(name, installType, opts); err != nil {
+		return nil, err
+	}
+
+	return inst.changes(base)
+}
+
+// changes compares the files written by the installation with their content in base.
+func (inst *installation) changes(base FileSystem) ([]Change, error) {
+	var changes []Change
+	seen := make(map[string]bool)
+	for _, w := range inst.written {
+		path := filepath.Clean(w.Path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		change := Change{Path: w.Path, Mode: w.Mode, Conflict: slices.Contains(inst.conflicts, w.Path)}
+
+		old, err := base.ReadFile(path)
+		switch {
+		case err == nil:
+			change.Exists, change.Old = true, old
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to read '%s': %w", w.Path, err)
+		}
+
+		change.New, err = inst.fs.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the new content of '%s': %w", w.Path, err)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// dryRunFS is a FileSystem that reads through to another one but keeps its writes in memory.
+type dryRunFS struct {
+	base    FileSystem
+	files   map[string][]byte
+	deleted map[string]bool
+}
+
+// newDryRunFS returns a FileSystem that never changes base.
+func newDryRunFS(base FileSystem) *dryRunFS {
+	return &dryRunFS{base: base, files: make(map[string][]byte), deleted: make(map[string]bool)}
+}
+
+func (d *dryRunFS) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+func (d *dryRunFS) CopyFile(src, dest string) error {
+	content, err := d.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return d.WriteFile(dest, content, 0o644)
+}
+
+func (d *dryRunFS) ReadFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	if content, ok := d.files[path]; ok {
+		return content, nil
+	}
+	if d.deleted[path] {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return d.base.ReadFile(path)
+}
+
+func (d *dryRunFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	path = filepath.Clean(path)
+	d.files[path] = append([]byte(nil), data...)
+	delete(d.deleted, path)
+
+	return nil
+}
+
+func (d *dryRunFS) Stat(path string) (os.FileInfo, error) {
+	path = filepath.Clean(path)
+	if content, ok := d.files[path]; ok {
+		return dryRunFileInfo{name: filepath.Base(path), size: int64(len(content))}, nil
+	}
+	if d.deleted[path] {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return d.base.Stat(path)
+}
+
+func (d *dryRunFS) Rename(oldpath, newpath string) error {
+	content, err := d.ReadFile(oldpath)
+	if err != nil {
+		return err
+	}
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	delete(d.files, oldpath)
+	d.deleted[oldpath] = true
+
+	return d.WriteFile(newpath, content, 0o644)
+}
+
+func (d *dryRunFS) Remove(path string) error {
+	if _, err := d.Stat(path); err != nil {
+		return err
+	}
+
+	path = filepath.Clean(path)
+	delete(d.files, path)
+	d.deleted[path] = true
+
+	return nil
+}
+
+func (d *dryRunFS) ReadDir(path string) ([]os.DirEntry, error) {
+	path = filepath.Clean(path)
+	entries, err := d.base.ReadDir(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	result := make([]os.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		if _, written := d.files[child]; !written && !d.deleted[child] {
+			result = append(result, entry)
+		}
+	}
+	for child, content := range d.files {
+		if filepath.Dir(child) == path {
+			result = append(result, fs.FileInfoToDirEntry(dryRunFileInfo{name: filepath.Base(child), size: int64(len(content))}))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+
+	return result, nil
+}
+
+// dryRunFileInfo describes a file written to a dryRunFS.
+type dryRunFileInfo struct {
+	name string
+	size int64
+}
+
+func (i dryRunFileInfo) Name() string       { return i.name }
+func (i dryRunFileInfo) Size() int64        { return i.size }
+func (i dryRunFileInfo) Mode() os.FileMode  { return 0o644 }
+func (i dryRunFileInfo) ModTime() time.Time { return time.Time{} }
+func (i dryRunFileInfo) IsDir() bool        { return false }
+func (i dryRunFileInfo) Sys() any           { return nil }
@@ -0,0 +1,73 @@
+package installer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dryRunFS(t *testing.T) {
+	t.Parallel()
+
+	base := &memFS{files: map[string][]byte{"/project/rules.md": []byte("old")}}
+	dry := newDryRunFS(base)
+
+	require.NoError(t, dry.Rename("/project/rules.md", "/project/rules.md.backup"))
+	require.NoError(t, dry.WriteFile("/project/new.md", []byte("new"), 0o644))
+
+	_, err := dry.ReadFile("/project/rules.md")
+	assert.True(t, os.IsNotExist(err))
+	_, err = dry.Stat("/project/rules.md")
+	assert.True(t, os.IsNotExist(err))
+
+	content, err := dry.ReadFile("/project/rules.md.backup")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+
+	info, err := dry.Stat("/project/new.md")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), info.Size())
+
+	// The underlying file system is left as is
+	assert.Equal(t, map[string][]byte{"/project/rules.md": []byte("old")}, base.files)
+}
+
+func Test_installation_changes(t *testing.T) {
+	t.Parallel()
+
+	base := &memFS{files: map[string][]byte{
+		"/project/same.md":    []byte("same"),
+		"/project/changed.md": []byte("old"),
+	}}
+	inst := newInstallation(newDryRunFS(base), "")
+	for path, content := range map[string]string{
+		"/project/same.md":    "same",
+		"/project/changed.md": "new",
+		"/project/created.md": "created",
+	} {
+		require.NoError(t, inst.fs.WriteFile(path, []byte(content), 0o644))
+	}
+	inst.written = []writtenFile{
+		{Path: "/project/same.md", Mode: editor.Local},
+		{Path: "/project/changed.md", Mode: editor.Local},
+		{Path: "/project/created.md", Mode: editor.Global},
+		{Path: "/project/changed.md", Mode: editor.Local},
+	}
+	inst.conflicts = []string{"/project/changed.md"}
+
+	changes, err := inst.changes(base)
+	require.NoError(t, err)
+
+	want := []Change{
+		{Path: "/project/same.md", Mode: editor.Local, Exists: true, Old: []byte("same"), New: []byte("same")},
+		{Path: "/project/changed.md", Mode: editor.Local, Exists: true, Old: []byte("old"), New: []byte("new"), Conflict: true},
+		{Path: "/project/created.md", Mode: editor.Global, New: []byte("created")},
+	}
+	assert.Equal(t, want, changes)
+	assert.False(t, changes[0].Changed())
+	assert.True(t, changes[1].Changed())
+	assert.True(t, changes[2].Changed())
+}
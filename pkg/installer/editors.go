@@ -0,0 +1,180 @@
+package installer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/plugin"
+)
+
+func init() {
+	// Register the built-in editors so that editors added by other programs can't take their names
+	for name, configFn := range editorConfigs {
+		editor.MustRegister(newFileEditor(name, configFn))
+	}
+}
+
+// fileEditor adapts an EditorConfig to the editor.Editor interface.
+type fileEditor struct {
+	name     string
+	configFn func() (EditorConfig, error)
+}
+
+// newFileEditor returns an editor whose configuration is built by configFn when it's used.
+func newFileEditor(name string, configFn func() (EditorConfig, error)) *fileEditor {
+	return &fileEditor{name: name, configFn: configFn}
+}
+
+// config returns the editor configuration.
+func (e *fileEditor) config() (EditorConfig, error) {
+	editorConfig, err := e.configFn()
+	if err != nil {
+		return EditorConfig{}, err
+	}
+	editorConfig.Name = e.name
+
+	return editorConfig, nil
+}
+
+// Name returns the editor name.
+func (e *fileEditor) Name() string {
+	return e.name
+}
+
+// SupportsGlobal reports whether the editor supports global mode.
+func (e *fileEditor) SupportsGlobal() bool {
+	editorConfig, err := e.config()
+	if err != nil {
+		return false
+	}
+
+	return editorConfig.GlobalSupported
+}
+
+// Destinations returns the destination file for the mode.
+func (e *fileEditor) Destinations(mode editor.Mode) ([]string, error) {
+	editorConfig, err := e.config()
+	if err != nil {
+		return nil, err
+	}
+
+	destPath, err := editorConfig.GetDestPath(string(mode))
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{destPath}, nil
+}
+
+// singleFilePath returns the local rules directory of editors that also read it as a single file.
+func (e *fileEditor) singleFilePath(mode editor.Mode) (string, bool) {
+	editorConfig, err := e.config()
+	if err != nil || mode != editor.Local || !editorConfig.LocalSingleFile {
+		return "", false
+	}
+
+	return editorConfig.LocalPath, true
+}
+
+// Render renders the rules with the editor's Render function or combines them into the destination.
+func (e *fileEditor) Render(rules editor.Rules) ([]editor.Output, error) {
+	editorConfig, err := e.config()
+	if err != nil {
+		return nil, err
+	}
+
+	return editorConfig.render(rules)
+}
+
+// Validate checks that the editor configuration can be built and is complete.
+func (e *fileEditor) Validate() error {
+	editorConfig, err := e.config()
+	if err != nil {
+		return err
+	}
+
+	if editorConfig.LocalFileName == "" {
+		return fmt.Errorf("no local file name")
+	}
+	if editorConfig.GlobalSupported && editorConfig.GlobalFileName == "" {
+		return fmt.Errorf("no global file name")
+	}
+	if !editor.IsValidFormat(editorConfig.Format) {
+		return fmt.Errorf("unknown format '%s'", editorConfig.Format)
+	}
+
+	return nil
+}
+
+// GlobalUnsupportedReason explains how to set global rules for the editor.
+func (e *fileEditor) GlobalUnsupportedReason() string {
+	editorConfig, err := e.config()
+	if err != nil {
+		return ""
+	}
+
+	return editorConfig.GlobalUnsupportedReason
+}
+
+// editorSet is the editors available to install rules for.
+type editorSet struct {
+	editors map[string]editor.Editor
+	// err is why the custom editors couldn't be loaded; the other editors are still available.
+	err error
+}
+
+// getEditors returns the editors loaded by loadEditors. They're loaded once per process, since discovering plugins
+// runs their executables.
+var getEditors = sync.OnceValue(loadEditors)
+
+// loadEditors returns the registered editors merged with the discovered plugins and
+// the custom editors defined in config.toml.
+// Plugins can't replace registered editors, and custom editors can't replace either.
+func loadEditors() editorSet {
+	editors := make(map[string]editor.Editor)
+	for _, name := range editor.Names() {
+		if e, ok := editor.Lookup(name); ok {
+			editors[name] = e
+		}
+	}
+
+	for _, p := range plugin.Discover() {
+		if _, ok := editors[p.Name()]; ok {
+			continue
+		}
+		editors[p.Name()] = p
+	}
+
+	definitions, err := config.GetCustomEditors()
+	if err != nil {
+		return editorSet{editors: editors, err: fmt.Errorf("failed to load custom editors from config.toml: %w", err)}
+	}
+
+	for _, definition := range definitions {
+		if _, ok := editors[definition.Name]; ok {
+			continue
+		}
+		editors[definition.Name] = newFileEditor(definition.Name, func() (EditorConfig, error) {
+			return newCustomEditorConfig(definition)
+		})
+	}
+
+	return editorSet{editors: editors}
+}
+
+// GetEditor returns the registered, plugin or custom editor with the name.
+func GetEditor(name string) (editor.Editor, error) {
+	set := getEditors()
+	if set.err != nil {
+		return nil, set.err
+	}
+
+	e, ok := set.editors[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported editor: %s", name)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,68 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_builtinEditorsAreRegistered(t *testing.T) {
+	t.Parallel()
+
+	for name := range editorConfigs {
+		e, ok := editor.Lookup(name)
+		require.True(t, ok, "Built-in editor %s should be registered", name)
+		assert.Equal(t, name, e.Name())
+		assert.NoError(t, e.Validate(), "Built-in editor %s should be valid", name)
+	}
+}
+
+func Test_markdownEditorsUseMarkdownFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"claude", "agents", "cline", "junie", "zed"} {
+		config, err := editorConfigs[name]()
+		require.NoError(t, err)
+		assert.Equal(t, editor.FormatMarkdown, config.Format, "Built-in editor %s should separate rule files with Markdown comments", name)
+	}
+}
+
+func Test_fileEditor_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  EditorConfig
+		wantErr bool
+	}{
+		{name: "Local only editor", config: EditorConfig{LocalPath: ".", LocalFileName: "rules.md"}},
+		{
+			name:   "Global editor",
+			config: EditorConfig{LocalPath: ".", LocalFileName: "rules.md", GlobalPath: "home", GlobalFileName: "rules.md", GlobalSupported: true},
+		},
+		{name: "Missing local file name", config: EditorConfig{LocalPath: "."}, wantErr: true},
+		{
+			name:    "Missing global file name",
+			config:  EditorConfig{LocalPath: ".", LocalFileName: "rules.md", GlobalPath: "home", GlobalSupported: true},
+			wantErr: true,
+		},
+		{name: "Unknown format", config: EditorConfig{LocalPath: ".", LocalFileName: "rules.md", Format: "html"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := newFileEditor("test", func() (EditorConfig, error) { return tt.config, nil })
+			err := e.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+const (
+	// geminiContextFileOption names the option that points Gemini CLI at an existing context file.
+	geminiContextFileOption = "context_file_name"
+	// geminiSettingsFileName is the name of Gemini CLI's settings file.
+	geminiSettingsFileName = "settings.json"
+	// geminiContextFileNameKey is the settings.json key that renames the context file.
+	geminiContextFileNameKey = "contextFileName"
+	// geminiContextFilePatch is the kind of patch that sets contextFileName, reverted by unsetGeminiContextFileName.
+	geminiContextFilePatch = "gemini-context-file"
+)
+
+// renderGemini combines the rule files into GEMINI.md. When the context_file_name option is set,
+// it instead points Gemini CLI's settings.json at that file so shared rules are not duplicated.
+func renderGemini(rules editor.Rules) ([]editor.Output, error) {
+	contextFileName := rules.Options[geminiContextFileOption]
+	if contextFileName == "" {
+		return []editor.Output{{Path: rules.Destination, Content: editor.Combine(rules.Files, editor.FormatMarkdown), Managed: true}}, nil
+	}
+
+	// Global settings live next to ~/.gemini/GEMINI.md, project settings in .gemini/
+	settingsDir := filepath.Dir(rules.Destination)
+	if rules.Mode == editor.Local {
+		settingsDir = filepath.Join(settingsDir, ".gemini")
+	}
+
+	return []editor.Output{{
+		Path: filepath.Join(settingsDir, geminiSettingsFileName),
+		Patch: func(existing []byte) ([]byte, error) {
+			return setGeminiContextFileName(existing, contextFileName)
+		},
+		PatchID: geminiContextFilePatch + ":" + contextFileName,
+	}}, nil
+}
+
+// settingsField is a top-level field of a JSON settings file.
+type settingsField struct {
+	key   string
+	value json.RawMessage
+}
+
+// setGeminiContextFileName sets contextFileName in the settings.json content, keeping the other settings in their order.
+func setGeminiContextFileName(settings []byte, contextFileName string) ([]byte, error) {
+	fields, err := parseSettingsFields(settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings file: %w", err)
+	}
+
+	name, err := json.Marshal(contextFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for i := range fields {
+		if fields[i].key == geminiContextFileNameKey {
+			fields[i].value = name
+			found = true
+		}
+	}
+	if !found {
+		fields = append(fields, settingsField{key: geminiContextFileNameKey, value: name})
+	}
+
+	return formatSettingsFields(fields)
+}
+
+// unsetGeminiContextFileName removes contextFileName from the settings.json content when it's still set to
+// contextFileName, keeping the other settings in their order. It also reports whether no settings are left.
+func unsetGeminiContextFileName(settings []byte, contextFileName string) ([]byte, bool, error) {
+	fields, err := parseSettingsFields(settings)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid settings file: %w", err)
+	}
+
+	kept := slices.DeleteFunc(slices.Clone(fields), func(field settingsField) bool {
+		var name string
+		if field.key != geminiContextFileNameKey || json.Unmarshal(field.value, &name) != nil {
+			return false
+		}
+
+		return name == contextFileName
+	})
+	if len(kept) == len(fields) {
+		return settings, len(fields) == 0, nil
+	}
+
+	content, err := formatSettingsFields(kept)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return content, len(kept) == 0, nil
+}
+
+// parseSettingsFields returns the top-level fields of a JSON object in the order they appear.
+func parseSettingsFields(settings []byte) ([]settingsField, error) {
+	if len(bytes.TrimSpace(settings)) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(settings))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("settings must be a JSON object")
+	}
+
+	var fields []settingsField
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid settings key: %v", token)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, settingsField{key: key, value: value})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after the settings object")
+	}
+
+	return fields, nil
+}
+
+// formatSettingsFields encodes the fields as a JSON object indented with two spaces.
+func formatSettingsFields(fields []settingsField) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n  ")
+		buf.Write(key)
+		buf.WriteString(": ")
+		if err := json.Indent(&buf, field.value, "  ", "  "); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,159 @@
+package installer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_renderGemini(t *testing.T) {
+	t.Parallel()
+
+	rules := []editor.Rule{{Path: "templates/gemini/local/GEMINI.md", Content: []byte("rule\n")}}
+
+	tests := []struct {
+		name        string
+		mode        string
+		destPath    string
+		options     map[string]string
+		wantPath    string
+		wantContent string
+		wantPatch   bool
+	}{
+		{
+			name:        "Write GEMINI.md",
+			mode:        "local",
+			destPath:    "GEMINI.md",
+			wantPath:    "GEMINI.md",
+			wantContent: "<!-- From GEMINI.md -->\nrule\n",
+		},
+		{
+			name:      "Point project settings at a shared file",
+			mode:      "local",
+			destPath:  "GEMINI.md",
+			options:   map[string]string{"context_file_name": "AGENTS.md"},
+			wantPath:  filepath.Join(".gemini", "settings.json"),
+			wantPatch: true,
+		},
+		{
+			name:      "Point global settings at a shared file",
+			mode:      "global",
+			destPath:  filepath.Join("home", ".gemini", "GEMINI.md"),
+			options:   map[string]string{"context_file_name": "AGENTS.md"},
+			wantPath:  filepath.Join("home", ".gemini", "settings.json"),
+			wantPatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderGemini(editor.Rules{Mode: editor.Mode(tt.mode), Key: "default", Destination: tt.destPath, Files: rules, Options: tt.options})
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, tt.wantPath, got[0].Path)
+			assert.Equal(t, tt.wantPatch, got[0].Patch != nil)
+			if !tt.wantPatch {
+				assert.Equal(t, tt.wantContent, string(got[0].Content))
+			}
+		})
+	}
+}
+
+func Test_setGeminiContextFileName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "No existing settings",
+			settings: "",
+			want:     "{\n  \"contextFileName\": \"AGENTS.md\"\n}\n",
+		},
+		{
+			name:     "Keep existing settings",
+			settings: `{"theme": "GitHub", "contextFileName": "GEMINI.md"}`,
+			want:     "{\n  \"theme\": \"GitHub\",\n  \"contextFileName\": \"AGENTS.md\"\n}\n",
+		},
+		{
+			name:     "Keep the order of existing settings",
+			settings: `{"theme": "GitHub", "autoAccept": true, "mcpServers": {"web": {"command": "web-mcp"}}}`,
+			want: "{\n  \"theme\": \"GitHub\",\n  \"autoAccept\": true,\n" +
+				"  \"mcpServers\": {\n    \"web\": {\n      \"command\": \"web-mcp\"\n    }\n  },\n" +
+				"  \"contextFileName\": \"AGENTS.md\"\n}\n",
+		},
+		{name: "Empty settings object", settings: "{}", want: "{\n  \"contextFileName\": \"AGENTS.md\"\n}\n"},
+		{name: "Invalid settings", settings: "{", wantErr: true},
+		{name: "Settings that aren't an object", settings: "[]", wantErr: true},
+		{name: "Data after the settings", settings: "{} {}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := setGeminiContextFileName([]byte(tt.settings), "AGENTS.md")
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_unsetGeminiContextFileName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		settings  string
+		want      string
+		wantEmpty bool
+		wantErr   bool
+	}{
+		{
+			name:     "Keep other settings",
+			settings: "{\n  \"theme\": \"GitHub\",\n  \"contextFileName\": \"AGENTS.md\"\n}\n",
+			want:     "{\n  \"theme\": \"GitHub\"\n}\n",
+		},
+		{
+			name:      "No settings left",
+			settings:  "{\n  \"contextFileName\": \"AGENTS.md\"\n}\n",
+			want:      "{}\n",
+			wantEmpty: true,
+		},
+		{
+			name:     "Keep a context file name set by the user",
+			settings: `{"contextFileName": "RULES.md"}`,
+			want:     `{"contextFileName": "RULES.md"}`,
+		},
+		{name: "Invalid settings", settings: "{", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, empty, err := unsetGeminiContextFileName([]byte(tt.settings), "AGENTS.md")
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantEmpty, empty)
+		})
+	}
+}
@@ -1,17 +1,25 @@
 package installer
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
-	"time"
 
+	"github.com/hashiiiii/airules/pkg/config"
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/project"
+	"github.com/hashiiiii/airules/pkg/render"
 	"github.com/mitchellh/go-homedir"
 )
 
+const defaultKey = "default"
+
 // InstallType represents the type of installation.
 type InstallType int
 
@@ -46,21 +54,35 @@ type EditorConfig struct {
 	GlobalPath      string
 	LocalFileName   string
 	GlobalFileName  string
+	// GlobalUnsupportedReason explains how to set global rules when GlobalSupported is false.
+	GlobalUnsupportedReason string
+	// Format selects how the default Render separates combined rule files. Empty means editor.FormatText.
+	Format string
+	// ManagedBlock writes the output of the default Render into a managed block of the destination file.
+	ManagedBlock bool
+	// LocalSingleFile writes local rules into a managed block of LocalPath when it's a file rather than a directory,
+	// for editors that read either a rules directory or a single rules file.
+	LocalSingleFile bool
+	// Render turns rule files into output files. When nil, the rules are combined into the destination file.
+	Render func(rules editor.Rules) ([]editor.Output, error)
 }
 
-// GetRuleFilePaths returns the rule file paths for the specified mode.
-func (c *EditorConfig) GetRuleFilePaths(mode string) ([]string, error) {
-	switch mode {
-	case "local":
-		return []string{filepath.Join(c.LocalPath, c.LocalFileName)}, nil
-	case "global":
+// keyPlaceholder is replaced with the rule-set key in destination paths.
+const keyPlaceholder = "{key}"
+
+// GetDestPath returns the destination file path for the specified mode.
+func (c *EditorConfig) GetDestPath(mode string) (string, error) {
+	switch editor.Mode(mode) {
+	case editor.Local:
+		return filepath.Join(c.LocalPath, c.LocalFileName), nil
+	case editor.Global:
 		if !c.GlobalSupported {
-			return nil, fmt.Errorf("global mode not supported for editor %s", c.Name)
+			return "", fmt.Errorf("global mode not supported for editor %s", c.Name)
 		}
 
-		return []string{filepath.Join(c.GlobalPath, c.GlobalFileName)}, nil
+		return filepath.Join(c.GlobalPath, c.GlobalFileName), nil
 	default:
-		return nil, fmt.Errorf("invalid mode: %s", mode)
+		return "", fmt.Errorf("invalid mode: %s", mode)
 	}
 }
 
@@ -83,7 +105,10 @@ var editorConfigs = map[string]func() (EditorConfig, error){
 		return EditorConfig{
 			LocalPath:       ".",
 			GlobalPath:      globalDestDir,
+			LocalFileName:   ".windsurfrules",
+			GlobalFileName:  "global_rules.md",
 			GlobalSupported: true,
+			ManagedBlock:    true,
 		}, nil
 	},
 	"cursor": func() (EditorConfig, error) {
@@ -94,11 +119,143 @@ var editorConfigs = map[string]func() (EditorConfig, error){
 		return EditorConfig{
 			LocalPath:       localDestDir,
 			GlobalPath:      localDestDir, // Use the same directory as local rules
-			GlobalSupported: true,         // Support global rules
+			LocalFileName:   "project_rules.mdc",
+			GlobalFileName:  "global_rules.mdc",
+			GlobalSupported: true, // Support global rules
+		}, nil
+	},
+	"claude": func() (EditorConfig, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return EditorConfig{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		// Claude Code reads CLAUDE.md at the project root and ~/.claude/CLAUDE.md as user memory
+		return EditorConfig{
+			LocalPath:       ".",
+			GlobalPath:      filepath.Join(home, ".claude"),
+			LocalFileName:   "CLAUDE.md",
+			GlobalFileName:  "CLAUDE.md",
+			GlobalSupported: true,
+			Format:          editor.FormatMarkdown,
+			ManagedBlock:    true,
+		}, nil
+	},
+	"copilot": func() (EditorConfig, error) {
+		// Copilot reads repository-wide instructions and path-scoped instruction files from .github
+		return EditorConfig{
+			LocalPath:               ".github",
+			LocalFileName:           "copilot-instructions.md",
+			GlobalSupported:         false,
+			GlobalUnsupportedReason: "Personal instructions for Copilot must be set in GitHub or in your editor's Copilot settings",
+			Render:                  renderCopilot,
+		}, nil
+	},
+	"agents": func() (EditorConfig, error) {
+		// AGENTS.md is read from the project root by Codex, Jules, Amp and other agents,
+		// with nested AGENTS.md files overriding it in subdirectories
+		return EditorConfig{
+			LocalPath:               ".",
+			LocalFileName:           "AGENTS.md",
+			GlobalSupported:         false,
+			Format:                  editor.FormatMarkdown,
+			ManagedBlock:            true,
+			GlobalUnsupportedReason: "The AGENTS.md convention only defines files inside a project",
+		}, nil
+	},
+	"cline": func() (EditorConfig, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return EditorConfig{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		// Cline reads every markdown file in the .clinerules directory and in its global rules directory,
+		// or a single .clinerules file in projects that use the older form
+		return EditorConfig{
+			LocalPath:       ".clinerules",
+			GlobalPath:      filepath.Join(home, "Documents", "Cline", "Rules"),
+			LocalFileName:   "airules.md",
+			GlobalFileName:  "airules.md",
+			GlobalSupported: true,
+			Format:          editor.FormatMarkdown,
+			LocalSingleFile: true,
+		}, nil
+	},
+	"roo": func() (EditorConfig, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return EditorConfig{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		// Roo Code reads .roo/rules and ~/.roo/rules, plus .roo/rules-<mode> for mode-specific rules
+		return EditorConfig{
+			LocalPath:       filepath.Join(".", ".roo", "rules"),
+			GlobalPath:      filepath.Join(home, ".roo", "rules"),
+			LocalFileName:   "airules.md",
+			GlobalFileName:  "airules.md",
+			GlobalSupported: true,
+			Render:          renderRoo,
+		}, nil
+	},
+	"gemini": func() (EditorConfig, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return EditorConfig{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		// Gemini CLI loads GEMINI.md from ~/.gemini, the project root and its subdirectories
+		return EditorConfig{
+			LocalPath:       ".",
+			GlobalPath:      filepath.Join(home, ".gemini"),
+			LocalFileName:   "GEMINI.md",
+			GlobalFileName:  "GEMINI.md",
+			GlobalSupported: true,
+			Render:          renderGemini,
+		}, nil
+	},
+	"junie": func() (EditorConfig, error) {
+		// Junie reads project guidelines from .junie/guidelines.md
+		return EditorConfig{
+			LocalPath:               ".junie",
+			LocalFileName:           "guidelines.md",
+			GlobalSupported:         false,
+			Format:                  editor.FormatMarkdown,
+			ManagedBlock:            true,
+			GlobalUnsupportedReason: "Junie only reads guidelines from the project's .junie directory",
+		}, nil
+	},
+	"zed": func() (EditorConfig, error) {
+		// Zed reads .rules at the project root, falling back to .cursorrules, CLAUDE.md, AGENTS.md and other known names
+		return EditorConfig{
+			LocalPath:               ".",
+			LocalFileName:           ".rules",
+			GlobalSupported:         false,
+			Format:                  editor.FormatMarkdown,
+			ManagedBlock:            true,
+			GlobalUnsupportedReason: "Global rules for Zed live in its Rules Library and must be set through the editor's Agent Panel",
+		}, nil
+	},
+	"aider": func() (EditorConfig, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return EditorConfig{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+
+		// Aider only reads conventions listed under read: in .aider.conf.yml or ~/.aider.conf.yml
+		return EditorConfig{
+			LocalPath:       ".",
+			GlobalPath:      filepath.Join(home, ".aider"),
+			LocalFileName:   "CONVENTIONS.md",
+			GlobalFileName:  "CONVENTIONS.md",
+			GlobalSupported: true,
+			Render:          newAiderRender(filepath.Join(home, aiderConfFileName)),
 		}, nil
 	},
 }
 
+// defaultGlobalUnsupportedReason is used for editors that don't explain why global mode is unsupported.
+const defaultGlobalUnsupportedReason = "Global rules for this editor must be set through the editor's settings interface"
+
 // FileSystem interface defines file system operations.
 type FileSystem interface {
 	MkdirAll(path string, perm os.FileMode) error
@@ -107,6 +264,8 @@ type FileSystem interface {
 	WriteFile(path string, data []byte, perm os.FileMode) error
 	Stat(path string) (os.FileInfo, error)
 	Rename(oldpath, newpath string) error
+	Remove(path string) error
+	ReadDir(path string) ([]os.DirEntry, error)
 }
 
 // DefaultFileSystem implements FileSystem interface using OS operations.
@@ -136,6 +295,14 @@ func (fs *DefaultFileSystem) Rename(oldpath, newpath string) error {
 	return os.Rename(oldpath, newpath)
 }
 
+func (fs *DefaultFileSystem) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (fs *DefaultFileSystem) ReadDir(path string) ([]os.DirEntry, error) {
+	return os.ReadDir(path)
+}
+
 // CopyFile copies a file from src to dest.
 func CopyFile(src, dest string) error {
 	srcFile, err := os.Open(src)
@@ -176,46 +343,73 @@ func CopyFile(src, dest string) error {
 	return nil
 }
 
-// GetSupportedEditors returns a list of supported editors.
+// GetSupportedEditors returns a sorted list of the registered and custom editors.
+// Custom editors are left out when config.toml can't be loaded; installing reports why.
 func GetSupportedEditors() []string {
-	editors := make([]string, 0, len(editorConfigs))
-	for editor := range editorConfigs {
-		editors = append(editors, editor)
+	editors := getEditors().editors
+	names := make([]string, 0, len(editors))
+	for name := range editors {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return editors
+	return names
 }
 
 // IsEditorSupported checks if an editor is supported.
-func IsEditorSupported(editor string) bool {
-	_, ok := editorConfigs[editor]
+func IsEditorSupported(name string) bool {
+	_, ok := getEditors().editors[name]
 
 	return ok
 }
 
 // IsGlobalModeSupported checks if the global mode is supported for the editor.
-func IsGlobalModeSupported(editor string) bool {
-	configFn, ok := editorConfigs[editor]
+func IsGlobalModeSupported(name string) bool {
+	e, ok := getEditors().editors[name]
 	if !ok {
 		return false
 	}
 
-	config, err := configFn()
-	if err != nil {
-		return false
+	return e.SupportsGlobal()
+}
+
+// GetGlobalUnsupportedReason explains how to set global rules for an editor without global mode support.
+func GetGlobalUnsupportedReason(name string) string {
+	e, ok := getEditors().editors[name]
+	if !ok {
+		return defaultGlobalUnsupportedReason
 	}
 
-	return config.GlobalSupported
+	explainer, ok := e.(editor.GlobalModeExplainer)
+	if !ok || explainer.GlobalUnsupportedReason() == "" {
+		return defaultGlobalUnsupportedReason
+	}
+
+	return explainer.GlobalUnsupportedReason()
+}
+
+// Options are the options of an installation.
+type Options struct {
+	// Key is the rule set to install.
+	Key string
+	// Vars are template variables that override the ones defined in config.toml.
+	Vars map[string]string
+	// SkipUnchangedBackups skips backing up files whose content is the same as their most recent backup.
+	SkipUnchangedBackups bool
 }
 
 // Install installs rules for the specified editor and installation type.
 func Install(editor string, installType InstallType) error {
-	return InstallWithKey(editor, installType, "default")
+	return InstallWithKey(editor, installType, defaultKey)
 }
 
 // validateInstallParams validates installation parameters.
 func validateInstallParams(editor string, installType InstallType, key string) error {
-	if !IsEditorSupported(editor) {
+	set := getEditors()
+	if set.err != nil {
+		return set.err
+	}
+	if _, ok := set.editors[editor]; !ok {
 		return fmt.Errorf("unsupported editor: %s", editor)
 	}
 
@@ -223,6 +417,10 @@ func validateInstallParams(editor string, installType InstallType, key string) e
 		return fmt.Errorf("key is required")
 	}
 
+	if err := checkCustomEditorConflict(editor); err != nil {
+		return err
+	}
+
 	if installType == Global && !IsGlobalModeSupported(editor) {
 		return fmt.Errorf("editor '%s' does not support global mode installation", editor)
 	}
@@ -230,152 +428,565 @@ func validateInstallParams(editor string, installType InstallType, key string) e
 	return nil
 }
 
-// getRulePaths gets rule paths based on installation type.
-func getRulePaths(fs FileSystem, editorConfig *EditorConfig, installType InstallType) ([]string, error) {
-	var localRulePaths, globalRulePaths []string
-	var err error
-
-	if installType == Local || installType == All {
-		localRulePaths, err = getLocalRulePaths(fs, editorConfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get local rule paths: %w", err)
+// getInstallModes returns the modes to install for the installation type.
+func getInstallModes(e editor.Editor, installType InstallType) []editor.Mode {
+	switch installType {
+	case Local:
+		return []editor.Mode{editor.Local}
+	case Global:
+		return []editor.Mode{editor.Global}
+	case All:
+		if e.SupportsGlobal() {
+			return []editor.Mode{editor.Local, editor.Global}
 		}
+
+		return []editor.Mode{editor.Local}
+	default:
+		return nil
 	}
+}
 
-	if (installType == Global || installType == All) && IsGlobalModeSupported(editorConfig.Name) {
-		globalRulePaths, err = getGlobalRulePaths(fs, editorConfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get global rule paths: %w", err)
+// getKeyModes returns the modes to install the rule-set key in. When the install type covers every mode, modes that
+// don't define the key are left out, so that a key defined only for local rules can be installed without a mode.
+func (inst *installation) getKeyModes(e editor.Editor, installType InstallType, key string) ([]editor.Mode, error) {
+	modes := getInstallModes(e, installType)
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("invalid install type: %s", installType)
+	}
+	if installType != All {
+		return modes, nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	defined := make([]editor.Mode, 0, len(modes))
+	skipped := make([]editor.Mode, 0, len(modes))
+	for _, mode := range modes {
+		if cfg.HasRuleSet(e.Name(), string(mode), key) {
+			defined = append(defined, mode)
+		} else {
+			skipped = append(skipped, mode)
 		}
 	}
 
-	rulePaths := make([]string, len(localRulePaths)+len(globalRulePaths))
-	copy(rulePaths, localRulePaths)
-	copy(rulePaths[len(localRulePaths):], globalRulePaths)
+	// When no mode defines the key, installing the first one reports it
+	if len(defined) == 0 {
+		return modes, nil
+	}
+	for _, mode := range skipped {
+		fmt.Fprintf(inst.out, "Skipped %s rules: rule set '%s' isn't defined for %s %s\n", mode, key, e.Name(), mode)
+	}
+
+	return defined, nil
+}
+
+// ruleSet is a rule set to install for a single mode.
+type ruleSet struct {
+	Mode editor.Mode
+	Key  string
+	// BaseDir places local destinations in a project subdirectory when non-empty.
+	BaseDir   string
+	RulePaths []string
+	Options   map[string]string
+	// Data is the data for rendering the rule files; its mode and key are set from the rule set.
+	Data render.Data
+	// IncludeDir is the directory that rule files include partials from.
+	IncludeDir string
+	// Template renders the rule files as Go templates; otherwise they're installed as they are.
+	Template bool
+}
+
+// installation installs rule sets and keeps track of the destinations it writes.
+type installation struct {
+	fs FileSystem
+	// out receives the messages about backups and merges.
+	out io.Writer
+	// stateDir is where the last-installed content of destinations is recorded; empty disables merging.
+	stateDir string
+	// configDir is the directory that the sources of destinations are recorded relative to.
+	configDir string
+	// backupDir is where backups are stored, grouped by project; backups are made next to the files when it's empty.
+	backupDir string
+	// projectDir is the absolute path of the project that local destinations are relative to.
+	projectDir string
+	// skipUnchangedBackups skips backing up files whose content is the same as their most recent backup.
+	skipUnchangedBackups bool
+	// written are the destinations written so far, in order.
+	written []writtenFile
+	// conflicts are the destinations written with conflict markers.
+	conflicts []string
+	// tx records the files replaced by the install in progress.
+	tx *transaction
+	// backups are the first backups made of the destinations written, by path.
+	backups map[string]string
+	// patched are the destinations that patches changed, by path, and whether the patches created them.
+	patched map[string]bool
+}
 
-	return rulePaths, nil
+// writtenFile is a destination written by an installation.
+type writtenFile struct {
+	Path   string
+	Editor string
+	Mode   editor.Mode
+	Key    string
+	// Sources are the rule files the destination was rendered from.
+	Sources []string
+	// TemplateVersion identifies the content of the sources.
+	TemplateVersion string
+	// Block is the ID of the managed block written to the destination, if any.
+	Block string
+	// Patch is the ID of the patch applied to the destination, if any.
+	Patch string
+}
+
+// newInstallation returns an installation that writes through fs and keeps its state in stateDir.
+func newInstallation(fs FileSystem, stateDir string) *installation {
+	return &installation{fs: fs, out: os.Stdout, stateDir: stateDir}
 }
 
 // InstallWithKey installs rules for the specified editor with a given key.
-func InstallWithKey(editor string, installType InstallType, key string) error {
-	if err := validateInstallParams(editor, installType, key); err != nil {
+func InstallWithKey(name string, installType InstallType, key string) error {
+	return InstallWithOptions(name, installType, Options{Key: key})
+}
+
+// InstallWithOptions installs rules for the specified editor with the given options.
+// A *ConflictError is returned when local edits of installed files couldn't be merged with the new rules.
+func InstallWithOptions(name string, installType InstallType, opts Options) error {
+	inst := newInstallation(NewOsFS(), "")
+	if err := inst.install(name, installType, opts); err != nil {
 		return err
 	}
 
-	fs := NewOsFS()
-	editorConfig, err := GetEditorConfig(editor)
+	if len(inst.conflicts) > 0 {
+		return &ConflictError{Paths: inst.conflicts}
+	}
+
+	return nil
+}
+
+// install installs rules for the editor through the installation's file system as a single transaction:
+// if any step fails, every file written so far is restored.
+func (inst *installation) install(name string, installType InstallType, opts Options) error {
+	inst.tx = newTransaction(inst.fs)
+	defer func() { inst.tx = nil }()
+
+	if err := inst.installModes(name, installType, opts); err != nil {
+		if rollbackErr := inst.tx.rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w; failed to restore the files written before the failure: %w", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return inst.tx.commit()
+}
+
+// installModes installs rules for the editor in every mode of the install type.
+func (inst *installation) installModes(name string, installType InstallType, opts Options) error {
+	key := opts.Key
+	if err := validateInstallParams(name, installType, key); err != nil {
+		return err
+	}
+
+	e, err := GetEditor(name)
 	if err != nil {
-		return fmt.Errorf("failed to get editor config: %w", err)
+		return err
+	}
+
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("editor '%s' can't be used: %w", name, err)
+	}
+
+	options, err := config.GetEditorOptions(name)
+	if err != nil {
+		return fmt.Errorf("failed to get editor options: %w", err)
 	}
 
-	rulePaths, err := getRulePaths(fs, &editorConfig, installType)
+	modes, err := inst.getKeyModes(e, installType, key)
 	if err != nil {
 		return err
 	}
 
+	data, err := newRenderData(name, opts.Vars)
+	if err != nil {
+		return err
+	}
+
+	includeDir, err := config.GetTemplatesDir()
+	if err != nil {
+		return fmt.Errorf("failed to get templates directory: %w", err)
+	}
+
+	if err := inst.useConfigDirs(); err != nil {
+		return err
+	}
+	inst.skipUnchangedBackups = opts.SkipUnchangedBackups
+
+	for _, mode := range modes {
+		set, err := getRuleSet(name, mode, key, data.Project.Detected)
+		if err != nil {
+			return err
+		}
+
+		set.Options, set.Data, set.IncludeDir = options, data, includeDir
+		if err := inst.installRuleSet(e, set); err != nil {
+			return fmt.Errorf("failed to install %s rules: %w", mode, err)
+		}
+
+		if mode == editor.Local {
+			if err := inst.installNested(e, set); err != nil {
+				return err
+			}
+		}
+	}
+
+	return inst.updateManifests(e.Name(), key, modes)
+}
+
+// useConfigDirs points the installation at the state, config and backup directories of the user's configuration.
+func (inst *installation) useConfigDirs() error {
+	var err error
+	inst.stateDir, err = config.GetStateDir()
+	if err != nil {
+		return fmt.Errorf("failed to get state directory: %w", err)
+	}
+
+	inst.configDir, err = config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	return inst.useBackupDir()
+}
+
+// getRuleSet returns the editor's rule set for the mode and key, with the conditional rule files for the detected tags.
+func getRuleSet(name string, mode editor.Mode, key string, detected []string) (ruleSet, error) {
+	rulePaths, err := getRulePaths(name, mode, key, detected)
+	if err != nil {
+		return ruleSet{}, err
+	}
+
+	template, err := config.IsTemplated(name, string(mode), key)
+	if err != nil {
+		return ruleSet{}, fmt.Errorf("failed to get %s rule set: %w", mode, err)
+	}
+
+	return ruleSet{Mode: mode, Key: key, RulePaths: rulePaths, Template: template}, nil
+}
+
+// getRulePaths returns the rule files of the rule set followed by the conditional rule files for the detected tags.
+func getRulePaths(name string, mode editor.Mode, key string, detected []string) ([]string, error) {
+	rulePaths, err := config.GetRuleFilePaths(name, string(mode), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s rule paths: %w", mode, err)
+	}
+
 	if len(rulePaths) == 0 {
-		return fmt.Errorf("no rules found for editor '%s'", editor)
+		return nil, fmt.Errorf("no %s rules found for editor '%s' with key '%s'", mode, name, key)
 	}
 
-	err = installLocal(fs, &editorConfig, rulePaths)
+	conditionalPaths, err := config.GetConditionalRuleFilePaths(name, string(mode), detected)
 	if err != nil {
-		return fmt.Errorf("failed to install rules: %w", err)
+		return nil, fmt.Errorf("failed to get conditional %s rule paths: %w", mode, err)
 	}
 
-	return nil
+	return append(rulePaths, conditionalPaths...), nil
 }
 
-// createBackup makes a backup of an existing file.
-func createBackup(fs FileSystem, filePath string) error {
-	// Check if the file exists
-	_, err := fs.Stat(filePath)
-	if os.IsNotExist(err) {
-		// No backup needed if the file doesn't exist
-		return nil
+// newRenderData returns the data for rendering the editor's rule files in the current project.
+func newRenderData(name string, vars map[string]string) (render.Data, error) {
+	info, err := project.Detect(".")
+	if err != nil {
+		return render.Data{}, fmt.Errorf("failed to detect the project: %w", err)
 	}
+
+	configVars, err := config.GetVars()
 	if err != nil {
-		return fmt.Errorf("failed to check file existence: %w", err)
+		return render.Data{}, fmt.Errorf("failed to get template variables: %w", err)
 	}
 
-	// Generate backup filename (original filename + .backup_YYYYMMDDhhmmss)
-	timestamp := time.Now().Format("20060102_150405")
-	backupPath := fmt.Sprintf("%s.backup_%s", filePath, timestamp)
+	merged := make(map[string]string, len(configVars)+len(vars))
+	maps.Copy(merged, configVars)
+	maps.Copy(merged, vars)
 
-	// Rename the file
-	err = fs.Rename(filePath, backupPath)
+	return render.Data{Project: info, Editor: name, Vars: merged}, nil
+}
+
+// installNested installs the rule files configured for project subdirectories,
+// using the local rule set for everything but the directories and rule files.
+func (inst *installation) installNested(e editor.Editor, local ruleSet) error {
+	nestedPaths, err := config.GetNestedRuleFilePaths(e.Name(), local.Key)
 	if err != nil {
-		return fmt.Errorf("failed to create backup file: %w", err)
+		return fmt.Errorf("failed to get nested rule paths: %w", err)
 	}
 
-	fmt.Printf("Created backup of existing file at: %s\n", backupPath)
+	dirs := make([]string, 0, len(nestedPaths))
+	for dir := range nestedPaths {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		set := local
+		set.BaseDir = dir
+		set.RulePaths = nestedPaths[dir]
+		if err := inst.installRuleSet(e, set); err != nil {
+			return fmt.Errorf("failed to install nested rules in %s: %w", dir, err)
+		}
+	}
 
 	return nil
 }
 
-// installLocal installs local rules using the specified rule files.
-func installLocal(fs FileSystem, config *EditorConfig, rulePaths []string) error {
-	destPath := filepath.Join(config.LocalPath, config.LocalFileName)
-	destDir := filepath.Dir(destPath)
-
-	if err := fs.MkdirAll(destDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", destDir, err)
+// installRuleSet installs the rule files of a rule set to the editor's destinations.
+func (inst *installation) installRuleSet(e editor.Editor, set ruleSet) error {
+	destPaths, err := e.Destinations(set.Mode)
+	if err != nil {
+		return err
 	}
 
-	// Create a backup of the existing file if it exists
-	if err := createBackup(fs, destPath); err != nil {
+	rules, err := readRuleFiles(inst.fs, set.RulePaths)
+	if err != nil {
 		return err
 	}
 
-	return combineAndWriteRules(fs, rulePaths, destPath)
+	written := writtenFile{
+		Editor:          e.Name(),
+		Mode:            set.Mode,
+		Key:             set.Key,
+		Sources:         inst.sources(set.RulePaths),
+		TemplateVersion: templateVersion(rules),
+	}
+
+	if set.Template {
+		data := set.Data
+		data.Mode = string(set.Mode)
+		data.Key = set.Key
+		renderer := &render.Renderer{IncludeDir: set.IncludeDir, ReadFile: inst.fs.ReadFile}
+		rules, err = renderRuleFiles(renderer, rules, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, destPath := range destPaths {
+		destPath, singleFile, err := inst.resolveDestPath(e, destPath, set)
+		if err != nil {
+			return err
+		}
+
+		outputs, err := e.Render(editor.Rules{
+			Mode:        set.Mode,
+			Key:         set.Key,
+			Destination: destPath,
+			Files:       rules,
+			Options:     set.Options,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render rules: %w", err)
+		}
+
+		for _, output := range outputs {
+			// A single rules file is the user's own file, so only a block of it is managed
+			if singleFile {
+				output.Managed = true
+			}
+			if set.Options[managedBlocksOption] == "false" {
+				output.Managed = false
+			}
+			blockID := managedBlockID(e.Name(), set.Key)
+			if err := inst.writeOutputFile(output, blockID); err != nil {
+				return err
+			}
+
+			written.Path, written.Block, written.Patch = output.Path, "", output.PatchID
+			if output.Managed {
+				written.Block = blockID
+			}
+			inst.written = append(inst.written, written)
+		}
+	}
+
+	return nil
 }
 
-// combineAndWriteRules combines multiple rule files and writes them to the destination.
-func combineAndWriteRules(fs FileSystem, rulePaths []string, destPath string) error {
-	var combinedContent strings.Builder
+// singleFileEditor is implemented by editors whose local rules directory can also be a single rules file.
+type singleFileEditor interface {
+	// singleFilePath returns the path of the rules directory that can be a single file in the mode, if any.
+	singleFilePath(mode editor.Mode) (string, bool)
+}
 
+// resolveDestPath expands the destination for the rule set and, for editors whose rules directory exists as
+// a single file, replaces the destination with that file and reports true.
+func (inst *installation) resolveDestPath(e editor.Editor, destPath string, set ruleSet) (string, bool, error) {
+	destPath, err := expandDestPath(destPath, set)
+	if err != nil {
+		return "", false, err
+	}
+
+	sf, ok := e.(singleFileEditor)
+	if !ok {
+		return destPath, false, nil
+	}
+	dir, ok := sf.singleFilePath(set.Mode)
+	if !ok {
+		return destPath, false, nil
+	}
+
+	singleFile := filepath.Join(set.BaseDir, dir)
+	if info, err := inst.fs.Stat(singleFile); err == nil && !info.IsDir() {
+		return singleFile, true, nil
+	}
+
+	return destPath, false, nil
+}
+
+// expandDestPath places a destination in the rule set's base directory and replaces the key placeholder.
+func expandDestPath(destPath string, set ruleSet) (string, error) {
+	destPath = filepath.Join(set.BaseDir, destPath)
+	if !strings.Contains(destPath, keyPlaceholder) {
+		return destPath, nil
+	}
+
+	if !filepath.IsLocal(set.Key) || strings.ContainsAny(set.Key, `/\`) {
+		return "", fmt.Errorf("rule-set key '%s' can't be used in a file path", set.Key)
+	}
+
+	return strings.ReplaceAll(destPath, keyPlaceholder, set.Key), nil
+}
+
+// render returns the output files for the rules, using the editor's Render function if it has one.
+func (c *EditorConfig) render(rules editor.Rules) ([]editor.Output, error) {
+	if c.Render != nil {
+		return c.Render(rules)
+	}
+
+	return []editor.Output{{Path: rules.Destination, Content: editor.Combine(rules.Files, c.Format), Managed: c.ManagedBlock}}, nil
+}
+
+// readRuleFiles reads the rule files at the given paths.
+func readRuleFiles(fs FileSystem, rulePaths []string) ([]editor.Rule, error) {
+	rules := make([]editor.Rule, 0, len(rulePaths))
 	for _, path := range rulePaths {
 		content, err := fs.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("rule file '%s' not found (run 'airules init' to create the default templates)", path)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to read rule file '%s': %w", path, err)
+			return nil, fmt.Errorf("failed to read rule file '%s': %w", path, err)
 		}
+		rules = append(rules, editor.Rule{Path: path, Content: content})
+	}
 
-		// Add file content with a separator
-		if combinedContent.Len() > 0 {
-			combinedContent.WriteString("\n\n")
-		}
-		combinedContent.WriteString(fmt.Sprintf("// From %s\n", filepath.Base(path)))
-		combinedContent.Write(content)
+	return rules, nil
+}
+
+// renderRuleFiles renders the rule files as templates.
+// Files that only override blocks of the files before them render to nothing and are left out.
+func renderRuleFiles(renderer *render.Renderer, rules []editor.Rule, data render.Data) ([]editor.Rule, error) {
+	files := make([]render.File, 0, len(rules))
+	for _, rule := range rules {
+		files = append(files, render.File{Path: rule.Path, Content: rule.Content})
 	}
 
-	// Write combined content to destination
-	if err := fs.WriteFile(destPath, []byte(combinedContent.String()), 0o644); err != nil {
-		return fmt.Errorf("failed to write to '%s': %w", destPath, err)
+	contents, err := renderer.RenderFiles(files, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render rule files: %w", err)
 	}
 
-	return nil
+	rendered := make([]editor.Rule, 0, len(rules))
+	for i, rule := range rules {
+		if len(bytes.TrimSpace(contents[i])) == 0 && len(bytes.TrimSpace(rule.Content)) > 0 {
+			continue
+		}
+		rendered = append(rendered, editor.Rule{Path: rule.Path, Content: contents[i]})
+	}
+
+	return rendered, nil
 }
 
-// getLocalRulePaths returns the paths of local rules.
-func getLocalRulePaths(fs FileSystem, config *EditorConfig) ([]string, error) {
-	paths, err := config.GetRuleFilePaths("local")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get local rule paths: %w", err)
+// outputContent returns the content to install for the output: its content, or the existing file with the output's
+// patch or managed block applied.
+func (inst *installation) outputContent(output editor.Output, blockID string, existing []byte, exists bool) ([]byte, error) {
+	switch {
+	case output.Patch != nil:
+		content, err := output.Patch(existing)
+		if err != nil {
+			return nil, err
+		}
+
+		// Patches that leave the file as it was have nothing for uninstall to revert
+		if !exists || !bytes.Equal(existing, content) {
+			inst.recordPatched(output.Path, !exists)
+		}
+
+		return content, nil
+	case output.Managed:
+		return replaceManagedBlock(existing, blockID, output.Content)
+	default:
+		return output.Content, nil
 	}
+}
 
-	return paths, nil
+// recordPatched records that a patch changed the destination, keeping whether the first patch created it.
+func (inst *installation) recordPatched(path string, created bool) {
+	path = filepath.Clean(path)
+	if inst.patched == nil {
+		inst.patched = make(map[string]bool)
+	}
+	if _, ok := inst.patched[path]; !ok {
+		inst.patched[path] = created
+	}
 }
 
-// getGlobalRulePaths returns the paths of global rules.
-func getGlobalRulePaths(fs FileSystem, config *EditorConfig) ([]string, error) {
-	if !config.GlobalSupported {
-		return nil, nil
+// writeOutputFile writes an output file, backing up any existing file at its path.
+// Managed outputs only replace the managed block with the ID in the existing file,
+// and changes made to the file since the last install are merged into the new content.
+func (inst *installation) writeOutputFile(output editor.Output, blockID string) error {
+	destDir := filepath.Dir(output.Path)
+	if err := inst.mkdirAll(destDir); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", destDir, err)
+	}
+
+	existing, err := inst.fs.ReadFile(output.Path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read '%s': %w", output.Path, err)
 	}
-	paths, err := config.GetRuleFilePaths("global")
+
+	content, err := inst.outputContent(output, blockID, existing, exists)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get global rule paths: %w", err)
+		return fmt.Errorf("failed to update '%s': %w", output.Path, err)
 	}
 
-	return paths, nil
+	installed := content
+	if exists {
+		content, err = inst.merge(output.Path, existing, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Leave the file alone when it already has the content, so that its modification time doesn't change
+	if exists && bytes.Equal(existing, content) {
+		fmt.Fprintf(inst.out, "%s is unchanged\n", output.Path)
+
+		return inst.recordInstalled(output.Path, installed)
+	}
+
+	// Create a backup of the existing file if it exists
+	if err := inst.backupDestination(output.Path); err != nil {
+		return err
+	}
+
+	if err := inst.writeFile(output.Path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write to '%s': %w", output.Path, err)
+	}
+
+	return inst.recordInstalled(output.Path, installed)
 }
 
 // NewOsFS creates a new OS file system implementation.
@@ -383,12 +994,17 @@ func NewOsFS() FileSystem {
 	return &DefaultFileSystem{}
 }
 
-// GetEditorConfig returns the configuration for the specified editor.
-func GetEditorConfig(editor string) (EditorConfig, error) {
-	configFn, ok := editorConfigs[editor]
+// GetEditorConfig returns the configuration for the specified built-in or custom editor.
+func GetEditorConfig(name string) (EditorConfig, error) {
+	e, err := GetEditor(name)
+	if err != nil {
+		return EditorConfig{}, err
+	}
+
+	fe, ok := e.(*fileEditor)
 	if !ok {
-		return EditorConfig{}, fmt.Errorf("unsupported editor: %s", editor)
+		return EditorConfig{}, fmt.Errorf("editor '%s' is not configured by an EditorConfig", name)
 	}
 
-	return configFn()
+	return fe.config()
 }
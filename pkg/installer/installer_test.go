@@ -0,0 +1,498 @@
+package installer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/project"
+	"github.com/hashiiiii/airules/pkg/render"
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memFileInfo is a minimal os.FileInfo for memFS.
+type memFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() os.FileMode  { return 0o644 }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return i.dir }
+func (i memFileInfo) Sys() any           { return nil }
+
+// memFS is an in-memory FileSystem used by tests.
+type memFS struct {
+	files map[string][]byte
+}
+
+func newMemFS(files map[string]string) *memFS {
+	m := &memFS{files: make(map[string][]byte)}
+	for path, content := range files {
+		m.files[filepath.Clean(path)] = []byte(content)
+	}
+
+	return m
+}
+
+func (m *memFS) MkdirAll(path string, perm os.FileMode) error { return nil }
+
+func (m *memFS) CopyFile(src, dest string) error {
+	content, err := m.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return m.WriteFile(dest, content, 0o644)
+}
+
+func (m *memFS) ReadFile(path string) ([]byte, error) {
+	content, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return content, nil
+}
+
+func (m *memFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	m.files[filepath.Clean(path)] = append([]byte(nil), data...)
+
+	return nil
+}
+
+func (m *memFS) Stat(path string) (os.FileInfo, error) {
+	content, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return memFileInfo{name: filepath.Base(path), size: int64(len(content))}, nil
+}
+
+func (m *memFS) Rename(oldpath, newpath string) error {
+	content, ok := m.files[filepath.Clean(oldpath)]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, filepath.Clean(oldpath))
+	m.files[filepath.Clean(newpath)] = content
+
+	return nil
+}
+
+func (m *memFS) Remove(path string) error {
+	path = filepath.Clean(path)
+	if _, ok := m.files[path]; ok {
+		delete(m.files, path)
+
+		return nil
+	}
+
+	// Directories are implied by the files in them
+	entries, err := m.ReadDir(path)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	if len(entries) > 0 {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrExist}
+	}
+
+	return nil
+}
+
+func (m *memFS) ReadDir(path string) ([]os.DirEntry, error) {
+	prefix := filepath.Clean(path) + string(filepath.Separator)
+	names := make(map[string]bool)
+	var entries []os.DirEntry
+	for name, content := range m.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		child, _, isDir := strings.Cut(rest, string(filepath.Separator))
+		if names[child] {
+			continue
+		}
+		names[child] = true
+		entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: child, size: int64(len(content)), dir: isDir}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+// backups returns the paths of backup files created next to path.
+func (m *memFS) backups(path string) []string {
+	var backups []string
+	for name := range m.files {
+		if strings.HasPrefix(name, filepath.Clean(path)+".backup_") {
+			backups = append(backups, name)
+		}
+	}
+
+	return backups
+}
+
+func Test_EditorConfig_GetDestPath(t *testing.T) {
+	t.Parallel()
+
+	editorConfig := EditorConfig{
+		Name:            "test",
+		LocalPath:       filepath.Join(".", ".test"),
+		GlobalPath:      filepath.Join("home", ".test"),
+		LocalFileName:   "local.md",
+		GlobalFileName:  "global.md",
+		GlobalSupported: true,
+	}
+
+	tests := []struct {
+		name    string
+		config  EditorConfig
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{name: "Local destination", config: editorConfig, mode: "local", want: filepath.Join(".test", "local.md")},
+		{name: "Global destination", config: editorConfig, mode: "global", want: filepath.Join("home", ".test", "global.md")},
+		{
+			name:    "Global destination when unsupported",
+			config:  EditorConfig{Name: "test", LocalPath: ".", LocalFileName: "local.md"},
+			mode:    "global",
+			wantErr: true,
+		},
+		{name: "Invalid mode", config: editorConfig, mode: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.config.GetDestPath(tt.mode)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_installRuleSet(t *testing.T) {
+	t.Parallel()
+
+	editorConfig := EditorConfig{
+		Name:            "test",
+		LocalPath:       "project",
+		GlobalPath:      "home",
+		LocalFileName:   "local.md",
+		GlobalFileName:  "global.md",
+		GlobalSupported: true,
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		mode        string
+		baseDir     string
+		rulePaths   []string
+		config      *EditorConfig
+		data        render.Data
+		template    bool
+		includeDir  string
+		wantPath    string
+		wantContent string
+		wantBackups int
+		wantErr     bool
+	}{
+		{
+			name:        "Install a single local rule file",
+			files:       map[string]string{"templates/a.md": "rule a\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			wantPath:    "project/local.md",
+			wantContent: "// From a.md\nrule a\n",
+		},
+		{
+			name:        "Combine global rule files",
+			files:       map[string]string{"templates/a.md": "rule a\n", "templates/b.md": "rule b\n"},
+			mode:        "global",
+			rulePaths:   []string{"templates/a.md", "templates/b.md"},
+			wantPath:    "home/global.md",
+			wantContent: "// From a.md\nrule a\n\n\n// From b.md\nrule b\n",
+		},
+		{
+			name:        "Back up an existing destination",
+			files:       map[string]string{"templates/a.md": "rule a\n", "project/local.md": "old\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			wantPath:    "project/local.md",
+			wantContent: "// From a.md\nrule a\n",
+			wantBackups: 1,
+		},
+		{
+			name:        "Install local rules in a project subdirectory",
+			files:       map[string]string{"templates/api.md": "rule api\n"},
+			mode:        "local",
+			baseDir:     "services/api",
+			rulePaths:   []string{"templates/api.md"},
+			wantPath:    "services/api/project/local.md",
+			wantContent: "// From api.md\nrule api\n",
+		},
+		{
+			name:        "Replace the key placeholder in the destination",
+			files:       map[string]string{"templates/a.md": "rule a\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			config:      &EditorConfig{Name: "test", LocalPath: "rules-{key}", LocalFileName: "{key}.md", Format: editor.FormatPlain},
+			wantPath:    "rules-default/default.md",
+			wantContent: "rule a\n",
+		},
+		{
+			name:      "Render rule templates",
+			files:     map[string]string{"templates/a.md": "{{ .Project.Name }} uses {{ .Project.Language }} in {{ .Mode }} for {{ .Vars.team }}\n"},
+			mode:      "local",
+			rulePaths: []string{"templates/a.md"},
+			template:  true,
+			config:    &EditorConfig{Name: "test", LocalPath: ".", LocalFileName: "local.md", Format: editor.FormatPlain},
+			data: render.Data{
+				Project: project.Info{Name: "airules", Language: "go"},
+				Vars:    map[string]string{"team": "platform"},
+			},
+			wantPath:    "local.md",
+			wantContent: "airules uses go in local for platform\n",
+		},
+		{
+			name:        "Include a partial",
+			files:       map[string]string{"templates/a.md": "{{ include \"fragments/x.md\" }}", "templates/fragments/x.md": "shared\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			template:    true,
+			config:      &EditorConfig{Name: "test", LocalPath: ".", LocalFileName: "local.md", Format: editor.FormatPlain},
+			includeDir:  "templates",
+			wantPath:    "local.md",
+			wantContent: "shared\n",
+		},
+		{
+			name: "Override a block of an extended rule set",
+			files: map[string]string{
+				"templates/base.md":    "# Rules\n{{ block \"testing\" . }}Unit tests.{{ end }}\n",
+				"templates/backend.md": "{{ define \"testing\" }}Integration tests.{{ end }}\n",
+			},
+			mode:        "local",
+			rulePaths:   []string{"templates/base.md", "templates/backend.md"},
+			template:    true,
+			wantPath:    "project/local.md",
+			wantContent: "// From base.md\n# Rules\nIntegration tests.\n",
+		},
+		{
+			name:        "Keep hand-written content around the managed block",
+			files:       map[string]string{"templates/a.md": "new\n", "CLAUDE.md": "# Mine\n<!-- airules:begin test/default -->\nold\n<!-- airules:end test/default -->\nMore\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			config:      &EditorConfig{Name: "test", LocalPath: ".", LocalFileName: "CLAUDE.md", Format: editor.FormatPlain, ManagedBlock: true},
+			wantPath:    "CLAUDE.md",
+			wantContent: "# Mine\n<!-- airules:begin test/default -->\nnew\n<!-- airules:end test/default -->\nMore\n",
+			wantBackups: 1,
+		},
+		{
+			name:      "Missing rule file",
+			files:     map[string]string{},
+			mode:      "local",
+			rulePaths: []string{"templates/missing.md"},
+			wantErr:   true,
+		},
+		{
+			name:        "Install rules that aren't templates as they are",
+			files:       map[string]string{"templates/a.md": "Helm values use {{ .Values.name }}\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			config:      &EditorConfig{Name: "test", LocalPath: ".", LocalFileName: "local.md", Format: editor.FormatPlain},
+			wantPath:    "local.md",
+			wantContent: "Helm values use {{ .Values.name }}\n",
+		},
+		{
+			name:        "Write a managed block into a single rules file in place of the rules directory",
+			files:       map[string]string{"templates/a.md": "rule a\n", ".clinerules": "# Mine\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			config:      &EditorConfig{Name: "test", LocalPath: ".clinerules", LocalFileName: "airules.md", Format: editor.FormatPlain, LocalSingleFile: true},
+			wantPath:    ".clinerules",
+			wantContent: "# Mine\n\n<!-- airules:begin test/default -->\nrule a\n<!-- airules:end test/default -->\n",
+			wantBackups: 1,
+		},
+		{
+			name:        "Write into the rules directory when it isn't a single file",
+			files:       map[string]string{"templates/a.md": "rule a\n", ".clinerules/mine.md": "# Mine\n"},
+			mode:        "local",
+			rulePaths:   []string{"templates/a.md"},
+			config:      &EditorConfig{Name: "test", LocalPath: ".clinerules", LocalFileName: "airules.md", Format: editor.FormatPlain, LocalSingleFile: true},
+			wantPath:    ".clinerules/airules.md",
+			wantContent: "rule a\n",
+		},
+		{
+			name:      "Invalid rule template",
+			files:     map[string]string{"templates/a.md": "{{ .Project.Name \n"},
+			mode:      "local",
+			rulePaths: []string{"templates/a.md"},
+			template:  true,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newMemFS(tt.files)
+			config := editorConfig
+			if tt.config != nil {
+				config = *tt.config
+			}
+			e := newFileEditor("test", func() (EditorConfig, error) { return config, nil })
+			set := ruleSet{
+				Mode:       editor.Mode(tt.mode),
+				Key:        "default",
+				BaseDir:    tt.baseDir,
+				RulePaths:  tt.rulePaths,
+				Data:       tt.data,
+				IncludeDir: tt.includeDir,
+				Template:   tt.template,
+			}
+			err := newInstallation(fs, "").installRuleSet(e, set)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := fs.ReadFile(tt.wantPath)
+			require.NoError(t, err, "Destination file should be written")
+			assert.Equal(t, tt.wantContent, string(got))
+			assert.Len(t, fs.backups(tt.wantPath), tt.wantBackups)
+		})
+	}
+}
+
+func Test_writeOutputFile(t *testing.T) {
+	t.Parallel()
+
+	appendLine := func(existing []byte) ([]byte, error) {
+		return append(existing, []byte("added\n")...), nil
+	}
+	keep := func(existing []byte) ([]byte, error) {
+		return existing, nil
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		output      editor.Output
+		wantContent string
+		wantBackups int
+		wantPatched map[string]bool
+	}{
+		{
+			name:        "Write content",
+			output:      editor.Output{Path: "out.md", Content: []byte("content\n")},
+			wantContent: "content\n",
+		},
+		{
+			name:        "Patch a missing file",
+			output:      editor.Output{Path: "out.md", Patch: appendLine},
+			wantContent: "added\n",
+			wantPatched: map[string]bool{"out.md": true},
+		},
+		{
+			name:        "Patch an existing file",
+			files:       map[string]string{"out.md": "existing\n"},
+			output:      editor.Output{Path: "out.md", Patch: appendLine},
+			wantContent: "existing\nadded\n",
+			wantBackups: 1,
+			wantPatched: map[string]bool{"out.md": false},
+		},
+		{
+			name:        "Patch that leaves a file as it was",
+			files:       map[string]string{"out.md": "existing\n"},
+			output:      editor.Output{Path: "out.md", Patch: keep},
+			wantContent: "existing\n",
+		},
+		{
+			name:        "Managed block in a hand-written file",
+			files:       map[string]string{"out.md": "# Notes\n"},
+			output:      editor.Output{Path: "out.md", Content: []byte("rules\n"), Managed: true},
+			wantContent: "# Notes\n\n<!-- airules:begin test/default -->\nrules\n<!-- airules:end test/default -->\n",
+			wantBackups: 1,
+		},
+		{
+			name:        "Leave an unchanged file alone",
+			files:       map[string]string{"out.md": "content\n"},
+			output:      editor.Output{Path: "out.md", Content: []byte("content\n")},
+			wantContent: "content\n",
+		},
+		{
+			name:        "Leave an unchanged managed block alone",
+			files:       map[string]string{"out.md": "# Notes\n\n<!-- airules:begin test/default -->\nrules\n<!-- airules:end test/default -->\n"},
+			output:      editor.Output{Path: "out.md", Content: []byte("rules\n"), Managed: true},
+			wantContent: "# Notes\n\n<!-- airules:begin test/default -->\nrules\n<!-- airules:end test/default -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newMemFS(tt.files)
+			inst := newInstallation(fs, "")
+			require.NoError(t, inst.writeOutputFile(tt.output, "test/default"))
+
+			got, err := fs.ReadFile(tt.output.Path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(got))
+			assert.Len(t, fs.backups(tt.output.Path), tt.wantBackups)
+			assert.Equal(t, tt.wantPatched, inst.patched)
+		})
+	}
+}
+
+func Test_GetGlobalUnsupportedReason(t *testing.T) {
+	// Load the editors without the plugins and config.toml of the machine running the tests
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+	homedir.Reset()
+	getEditors = sync.OnceValue(loadEditors)
+	t.Cleanup(func() {
+		homedir.Reset()
+		getEditors = sync.OnceValue(loadEditors)
+	})
+
+	tests := []struct {
+		name   string
+		editor string
+		want   string
+	}{
+		{name: "Editor with its own reason", editor: "zed", want: "Global rules for Zed live in its Rules Library"},
+		{name: "Editor without its own reason", editor: "windsurf", want: defaultGlobalUnsupportedReason},
+		{name: "Unknown editor", editor: "unknown", want: defaultGlobalUnsupportedReason},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, GetGlobalUnsupportedReason(tt.editor), tt.want)
+		})
+	}
+}
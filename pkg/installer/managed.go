@@ -0,0 +1,166 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// managedBlocksOption is the editor option that turns managed blocks off when set to "false",
+// so that airules owns and overwrites the whole destination file.
+const managedBlocksOption = "managed_blocks"
+
+// managedBlockID returns the ID of the managed block of an editor's rule set.
+func managedBlockID(editorName, key string) string {
+	return editorName + "/" + key
+}
+
+// managedBlockPrefix returns the prefix of the IDs of the editor's managed blocks for the block with the ID.
+func managedBlockPrefix(id string) string {
+	editorName, _, _ := strings.Cut(id, "/")
+
+	return managedBlockID(editorName, "")
+}
+
+// managedBlockIDs returns the IDs of the managed blocks in content, in the order they appear.
+func managedBlockIDs(content []byte) []string {
+	beginMarker, _ := managedBlockMarkers("")
+	prefix := bytes.TrimSuffix(beginMarker, []byte(" -->"))
+
+	var ids []string
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if rest, ok := bytes.CutPrefix(line, prefix); ok && bytes.HasSuffix(rest, []byte(" -->")) {
+			ids = append(ids, string(bytes.TrimSuffix(rest, []byte(" -->"))))
+		}
+	}
+
+	return ids
+}
+
+// managedBlockMarkers returns the lines that start and end the managed block with the ID.
+func managedBlockMarkers(id string) (begin, end []byte) {
+	return []byte(fmt.Sprintf("<!-- airules:begin %s -->", id)), []byte(fmt.Sprintf("<!-- airules:end %s -->", id))
+}
+
+// findManagedBlock returns the start and end offsets of the managed block with the ID in content,
+// including its markers, and false if there is none.
+func findManagedBlock(content []byte, id string) (start, end int, found bool, err error) {
+	beginMarker, endMarker := managedBlockMarkers(id)
+
+	start = bytes.Index(content, beginMarker)
+	endIndex := bytes.Index(content, endMarker)
+	switch {
+	case start < 0 && endIndex < 0:
+		return 0, 0, false, nil
+	case start < 0:
+		return 0, 0, false, fmt.Errorf("managed block '%s' has an end marker but no begin marker", id)
+	case endIndex < start:
+		return 0, 0, false, fmt.Errorf("managed block '%s' has no end marker after its begin marker", id)
+	}
+
+	return start, endIndex + len(endMarker), true, nil
+}
+
+// replaceManagedBlock returns existing with the managed block with the ID set to content.
+// The editor's blocks of other rule sets are replaced too, with the block taking the place of the first of them,
+// so that switching rule sets doesn't leave the old rules behind.
+// The block is appended when existing has none, and the content around it is kept as is.
+func replaceManagedBlock(existing []byte, id string, content []byte) ([]byte, error) {
+	existing, err := replaceOtherManagedBlocks(existing, id)
+	if err != nil {
+		return nil, err
+	}
+
+	beginMarker, endMarker := managedBlockMarkers(id)
+
+	var block bytes.Buffer
+	block.Write(beginMarker)
+	block.WriteByte('\n')
+	block.Write(content)
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		block.WriteByte('\n')
+	}
+	block.Write(endMarker)
+
+	start, end, found, err := findManagedBlock(existing, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bytes.Buffer
+	switch {
+	case found:
+		result.Write(existing[:start])
+		result.Write(block.Bytes())
+		result.Write(existing[end:])
+	case len(bytes.TrimSpace(existing)) == 0:
+		result.Write(block.Bytes())
+		result.WriteByte('\n')
+	default:
+		result.Write(bytes.TrimRight(existing, "\n"))
+		result.WriteString("\n\n")
+		result.Write(block.Bytes())
+		result.WriteByte('\n')
+	}
+
+	return result.Bytes(), nil
+}
+
+// replaceOtherManagedBlocks returns existing without the blocks of the editor's other rule sets than the block with
+// the ID. When existing has no block with the ID, the first of them is emptied and given the ID instead.
+func replaceOtherManagedBlocks(existing []byte, id string) ([]byte, error) {
+	_, _, found, err := findManagedBlock(existing, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := managedBlockPrefix(id)
+	for _, other := range managedBlockIDs(existing) {
+		if other == id || !strings.HasPrefix(other, prefix) {
+			continue
+		}
+
+		if found {
+			if existing, _, err = removeManagedBlock(existing, other); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		start, end, _, err := findManagedBlock(existing, other)
+		if err != nil {
+			return nil, err
+		}
+		beginMarker, endMarker := managedBlockMarkers(id)
+		existing = slices.Concat(existing[:start], beginMarker, []byte("\n"), endMarker, existing[end:])
+		found = true
+	}
+
+	return existing, nil
+}
+
+// removeManagedBlock returns existing without the managed block with the ID and the blank lines that separated it
+// from the content around it, and false if there is no such block.
+func removeManagedBlock(existing []byte, id string) ([]byte, bool, error) {
+	start, end, found, err := findManagedBlock(existing, id)
+	if err != nil || !found {
+		return existing, false, err
+	}
+
+	before := bytes.TrimRight(existing[:start], "\n")
+	after := bytes.TrimLeft(existing[end:], "\n")
+
+	var result bytes.Buffer
+	result.Write(before)
+	if len(before) > 0 && len(after) > 0 {
+		result.WriteString("\n\n")
+	} else if len(before) > 0 {
+		result.WriteByte('\n')
+	}
+	result.Write(after)
+
+	return result.Bytes(), true, nil
+}
@@ -0,0 +1,112 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_replaceManagedBlock(t *testing.T) {
+	t.Parallel()
+
+	const (
+		begin = "<!-- airules:begin claude/default -->\n"
+		end   = "<!-- airules:end claude/default -->"
+	)
+
+	tests := []struct {
+		name     string
+		existing string
+		content  string
+		want     string
+		wantErr  bool
+	}{
+		{name: "New file", content: "rules\n", want: begin + "rules\n" + end + "\n"},
+		{name: "Content without a trailing newline", content: "rules", want: begin + "rules\n" + end + "\n"},
+		{
+			name:     "Append to a hand-written file",
+			existing: "# Team notes\nKeep this.\n\n",
+			content:  "rules\n",
+			want:     "# Team notes\nKeep this.\n\n" + begin + "rules\n" + end + "\n",
+		},
+		{
+			name:     "Replace the block in place",
+			existing: "before\n" + begin + "old\nrules\n" + end + "\nafter\n",
+			content:  "new\n",
+			want:     "before\n" + begin + "new\n" + end + "\nafter\n",
+		},
+		{
+			name:     "Replace the block of another rule set in place",
+			existing: "before\n<!-- airules:begin claude/backend -->\nbackend\n<!-- airules:end claude/backend -->\nafter\n",
+			content:  "rules\n",
+			want:     "before\n" + begin + "rules\n" + end + "\nafter\n",
+		},
+		{
+			name: "Remove the blocks of other rule sets next to the block",
+			existing: "<!-- airules:begin claude/backend -->\nbackend\n<!-- airules:end claude/backend -->\n\n" +
+				begin + "old\n" + end + "\n",
+			content: "rules\n",
+			want:    begin + "rules\n" + end + "\n",
+		},
+		{
+			name:     "Keep blocks of other editors",
+			existing: "<!-- airules:begin agents/default -->\nagents\n<!-- airules:end agents/default -->\n",
+			content:  "rules\n",
+			want:     "<!-- airules:begin agents/default -->\nagents\n<!-- airules:end agents/default -->\n\n" + begin + "rules\n" + end + "\n",
+		},
+		{name: "Missing end marker", existing: begin + "old\n", content: "new\n", wantErr: true},
+		{name: "Missing begin marker", existing: "old\n" + end + "\n", content: "new\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := replaceManagedBlock([]byte(tt.existing), "claude/default", []byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_removeManagedBlock(t *testing.T) {
+	t.Parallel()
+
+	const block = "<!-- airules:begin claude/default -->\nrules\n<!-- airules:end claude/default -->"
+
+	tests := []struct {
+		name      string
+		existing  string
+		want      string
+		wantFound bool
+		wantErr   bool
+	}{
+		{name: "Appended block", existing: "# Notes\n\n" + block + "\n", want: "# Notes\n", wantFound: true},
+		{name: "Block between content", existing: "before\n\n" + block + "\n\nafter\n", want: "before\n\nafter\n", wantFound: true},
+		{name: "Only the block", existing: block + "\n", want: "", wantFound: true},
+		{name: "No block", existing: "# Notes\n", want: "# Notes\n"},
+		{name: "Missing end marker", existing: "<!-- airules:begin claude/default -->\nrules\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, found, err := removeManagedBlock([]byte(tt.existing), "claude/default")
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/manifest"
+	"github.com/hashiiiii/airules/pkg/version"
+)
+
+// manifestPath returns the path of the manifest that records the installs in the mode.
+func (inst *installation) manifestPath(mode editor.Mode) string {
+	if mode == editor.Global {
+		return filepath.Join(inst.stateDir, manifest.GlobalFileName)
+	}
+
+	return manifest.LocalFileName
+}
+
+// readManifest reads the manifest at path, which is empty when the file doesn't exist.
+func (inst *installation) readManifest(path string) (*manifest.Manifest, error) {
+	data, err := inst.fs.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read manifest '%s': %w", path, err)
+	}
+
+	m, err := manifest.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %w", path, err)
+	}
+
+	return m, nil
+}
+
+// updateManifests replaces the entries of the editor's rule-set key in the manifests of the modes with the files written.
+func (inst *installation) updateManifests(editorName, key string, modes []editor.Mode) error {
+	for _, mode := range modes {
+		files, err := inst.manifestFiles(mode)
+		if err != nil {
+			return err
+		}
+
+		path := inst.manifestPath(mode)
+		m, err := inst.readManifest(path)
+		if err != nil {
+			return err
+		}
+		inst.setOriginals(m, files)
+		files = inst.setPatches(m, files)
+		m.Replace(editorName, mode, key, files)
+		if err := inst.writeManifest(path, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeManifest writes the manifest to path, or removes the file when the manifest has no files left.
+func (inst *installation) writeManifest(path string, m *manifest.Manifest) error {
+	if len(m.Files) == 0 {
+		if err := inst.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove manifest '%s': %w", path, err)
+		}
+
+		return nil
+	}
+
+	m.Version = manifest.Version
+	m.AirulesVersion = version.Version
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Installs that change nothing leave the manifest as is
+	if existing, err := inst.fs.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+
+	if err := inst.mkdirAll(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create directory for manifest: %w", err)
+	}
+	if err := inst.writeFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// manifestFiles returns the manifest entries of the files written in the mode.
+// A file or managed block written more than once is recorded with its last rule set.
+func (inst *installation) manifestFiles(mode editor.Mode) ([]manifest.File, error) {
+	var files []manifest.File
+	index := make(map[[2]string]int)
+	for _, w := range inst.written {
+		if w.Mode != mode {
+			continue
+		}
+
+		content, err := inst.fs.ReadFile(w.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read installed file '%s': %w", w.Path, err)
+		}
+
+		file := manifest.File{
+			Path:            filepath.ToSlash(w.Path),
+			Editor:          w.Editor,
+			Mode:            w.Mode,
+			Key:             w.Key,
+			Sources:         w.Sources,
+			TemplateVersion: w.TemplateVersion,
+			Hash:            manifest.Hash(content),
+			Block:           w.Block,
+			Patch:           w.Patch,
+		}
+		id := [2]string{w.Path, w.Block}
+		if i, ok := index[id]; ok {
+			files[i] = file
+
+			continue
+		}
+		index[id] = len(files)
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// setOriginals records the backups of the files that airules replaced when it first installed the files.
+// Files already in the manifest keep the backup recorded then, since later backups hold content airules wrote.
+func (inst *installation) setOriginals(m *manifest.Manifest, files []manifest.File) {
+	for i := range files {
+		// Managed blocks and patches leave the rest of the file alone, so there's nothing to restore
+		if files[i].Block != "" || files[i].Patch != "" {
+			continue
+		}
+
+		j := slices.IndexFunc(m.Files, func(f manifest.File) bool { return f.Path == files[i].Path && f.Block == "" })
+		if j >= 0 {
+			files[i].Original = m.Files[j].Original
+
+			continue
+		}
+		if backup, ok := inst.backups[filepath.Clean(filepath.FromSlash(files[i].Path))]; ok {
+			files[i].Original = inst.recordedPath(backup)
+		}
+	}
+}
+
+// setPatches records whether patches created the patched files, and leaves out the files that a patch didn't change
+// when it was first applied, since the change that uninstall would revert was made by the user.
+// Files already in the manifest keep what was recorded then.
+func (inst *installation) setPatches(m *manifest.Manifest, files []manifest.File) []manifest.File {
+	kept := files[:0]
+	for _, file := range files {
+		if file.Patch == "" {
+			kept = append(kept, file)
+
+			continue
+		}
+
+		j := slices.IndexFunc(m.Files, func(f manifest.File) bool { return f.Path == file.Path && f.Patch == file.Patch })
+		created, patched := inst.patched[filepath.Clean(filepath.FromSlash(file.Path))]
+		switch {
+		case j >= 0:
+			file.Created = m.Files[j].Created
+		case patched:
+			file.Created = created
+		default:
+			continue
+		}
+		kept = append(kept, file)
+	}
+
+	return kept
+}
+
+// sources returns the rule paths as recorded in manifests.
+func (inst *installation) sources(rulePaths []string) []string {
+	sources := make([]string, 0, len(rulePaths))
+	for _, path := range rulePaths {
+		sources = append(sources, inst.recordedPath(path))
+	}
+
+	return sources
+}
+
+// recordedPath returns the path as recorded in manifests: relative to the config directory when it's in it,
+// so that manifests committed to a project don't depend on the home directory.
+func (inst *installation) recordedPath(path string) string {
+	if inst.configDir != "" {
+		if rel, err := filepath.Rel(inst.configDir, path); err == nil && filepath.IsLocal(rel) {
+			path = rel
+		}
+	}
+
+	return filepath.ToSlash(path)
+}
+
+// resolveRecordedPath returns the path of a file recorded in a manifest.
+func (inst *installation) resolveRecordedPath(recorded string) string {
+	path := filepath.FromSlash(recorded)
+	if inst.configDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(inst.configDir, path)
+	}
+
+	return path
+}
+
+// templateVersion returns a short hash that identifies the content of the rule files.
+func templateVersion(rules []editor.Rule) string {
+	h := sha256.New()
+	for _, rule := range rules {
+		// Prefix each file with its length so that moving content between files changes the hash
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(rule.Content)))
+		h.Write(size[:])
+		h.Write(rule.Content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -0,0 +1,140 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_installation_updateManifests(t *testing.T) {
+	t.Parallel()
+
+	existing := &manifest.Manifest{Version: manifest.Version, Files: []manifest.File{
+		{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "default", Hash: "sha256:00"},
+		{Path: ".cursor/rules/old.mdc", Editor: "cursor", Mode: editor.Local, Key: "old", Hash: "sha256:00"},
+	}}
+	data, err := existing.Marshal()
+	require.NoError(t, err)
+
+	fs := &memFS{files: map[string][]byte{
+		manifest.LocalFileName:        data,
+		".cursor/rules/backend.mdc":   []byte("first"),
+		"/home/.cursor/rules/all.mdc": []byte("global"),
+	}}
+	inst := newInstallation(fs, "/state")
+	inst.configDir = "/config"
+	inst.written = []writtenFile{
+		{
+			Path:            ".cursor/rules/backend.mdc",
+			Editor:          "cursor",
+			Mode:            editor.Local,
+			Key:             "backend",
+			Sources:         inst.sources([]string{"/config/templates/cursor/local/backend.mdc", "/elsewhere/extra.md"}),
+			TemplateVersion: "abc",
+		},
+		{Path: "/home/.cursor/rules/all.mdc", Editor: "cursor", Mode: editor.Global, Key: "backend", Block: "cursor/backend"},
+	}
+
+	require.NoError(t, inst.updateManifests("cursor", "backend", []editor.Mode{editor.Local, editor.Global}))
+
+	local, err := manifest.Parse(fs.files[manifest.LocalFileName])
+	require.NoError(t, err)
+	assert.Equal(t, []manifest.File{
+		{
+			Path:            ".cursor/rules/backend.mdc",
+			Editor:          "cursor",
+			Mode:            editor.Local,
+			Key:             "backend",
+			Sources:         []string{"templates/cursor/local/backend.mdc", "/elsewhere/extra.md"},
+			TemplateVersion: "abc",
+			Hash:            manifest.Hash([]byte("first")),
+		},
+		{Path: ".cursor/rules/old.mdc", Editor: "cursor", Mode: editor.Local, Key: "old", Hash: "sha256:00"},
+		{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "default", Hash: "sha256:00"},
+	}, local.Files)
+
+	global, err := manifest.Parse(fs.files["/state/"+manifest.GlobalFileName])
+	require.NoError(t, err)
+	assert.Equal(t, []manifest.File{
+		{
+			Path:   "/home/.cursor/rules/all.mdc",
+			Editor: "cursor",
+			Mode:   editor.Global,
+			Key:    "backend",
+			Hash:   manifest.Hash([]byte("global")),
+			Block:  "cursor/backend",
+		},
+	}, global.Files)
+}
+
+func Test_templateVersion(t *testing.T) {
+	t.Parallel()
+
+	version := templateVersion([]editor.Rule{{Path: "a.md", Content: []byte("ab")}, {Path: "b.md", Content: []byte("c")}})
+	assert.Len(t, version, 12)
+	assert.Equal(t, version, templateVersion([]editor.Rule{{Path: "other.md", Content: []byte("ab")}, {Path: "b.md", Content: []byte("c")}}))
+	assert.NotEqual(t, version, templateVersion([]editor.Rule{{Path: "a.md", Content: []byte("a")}, {Path: "b.md", Content: []byte("bc")}}))
+}
+
+func Test_installation_updateManifests_patches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing []manifest.File
+		patched  map[string]bool
+		want     []manifest.File
+	}{
+		{
+			name:    "Record a patch that created the file",
+			patched: map[string]bool{".aider.conf.yml": true},
+			want:    []manifest.File{{Path: ".aider.conf.yml", Patch: "aider-read:CONVENTIONS.md", Created: true}},
+		},
+		{
+			name:    "Record a patch that changed an existing file",
+			patched: map[string]bool{".aider.conf.yml": false},
+			want:    []manifest.File{{Path: ".aider.conf.yml", Patch: "aider-read:CONVENTIONS.md"}},
+		},
+		{
+			name: "Leave out a patch that the file already had",
+		},
+		{
+			name:     "Keep a patch recorded by an earlier install",
+			existing: []manifest.File{{Path: ".aider.conf.yml", Patch: "aider-read:CONVENTIONS.md", Created: true}},
+			want:     []manifest.File{{Path: ".aider.conf.yml", Patch: "aider-read:CONVENTIONS.md", Created: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i := range tt.existing {
+				tt.existing[i].Editor, tt.existing[i].Mode, tt.existing[i].Key = "aider", editor.Local, "default"
+			}
+			data, err := (&manifest.Manifest{Version: manifest.Version, Files: tt.existing}).Marshal()
+			require.NoError(t, err)
+
+			const conf = "read:\n  - CONVENTIONS.md\n"
+			fs := newMemFS(map[string]string{".aider.conf.yml": conf})
+			fs.files[manifest.LocalFileName] = data
+			inst := newInstallation(fs, "")
+			inst.patched = tt.patched
+			inst.written = []writtenFile{
+				{Path: ".aider.conf.yml", Editor: "aider", Mode: editor.Local, Key: "default", Patch: "aider-read:CONVENTIONS.md"},
+			}
+
+			require.NoError(t, inst.updateManifests("aider", "default", []editor.Mode{editor.Local}))
+
+			m, err := manifest.Parse(fs.files[manifest.LocalFileName])
+			require.NoError(t, err)
+			for i := range tt.want {
+				tt.want[i].Editor, tt.want[i].Mode, tt.want[i].Key, tt.want[i].Hash = "aider", editor.Local, "default", manifest.Hash([]byte(conf))
+			}
+			assert.Equal(t, tt.want, m.Files)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashiiiii/airules/pkg/diff"
+)
+
+// installedDirName is the state subdirectory that holds the last-installed content of each destination.
+const installedDirName = "installed"
+
+// mergeLabels name the versions in conflict markers.
+var mergeLabels = diff.Labels{Ours: "current", Base: "last installed by airules", Theirs: "airules"}
+
+// ConflictError reports destinations where local edits and rule changes couldn't be merged.
+// The destinations are written with conflict markers.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("merge conflicts in %s", strings.Join(e.Paths, ", "))
+}
+
+// installedPath returns where the last-installed content of the destination is recorded.
+func (inst *installation) installedPath(destPath string) (string, error) {
+	absPath, err := filepath.Abs(destPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s': %w", destPath, err)
+	}
+	sum := sha256.Sum256([]byte(absPath))
+
+	return filepath.Join(inst.stateDir, installedDirName, hex.EncodeToString(sum[:])), nil
+}
+
+// merge merges the changes made to the destination since the last install into the new content.
+// The content is returned as is when nothing was recorded for the destination.
+func (inst *installation) merge(destPath string, existing, content []byte) ([]byte, error) {
+	if inst.stateDir == "" {
+		return content, nil
+	}
+
+	installedPath, err := inst.installedPath(destPath)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := inst.fs.ReadFile(installedPath)
+	if os.IsNotExist(err) {
+		return content, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last installed content of '%s': %w", destPath, err)
+	}
+
+	if bytes.Equal(existing, base) {
+		return content, nil
+	}
+
+	// Merging again would nest conflict markers, so the file is left as is until its conflicts are resolved
+	if diff.HasConflicts(existing, mergeLabels) {
+		inst.conflicts = append(inst.conflicts, destPath)
+		fmt.Fprintf(inst.out, "%s still has conflict markers; resolve them and install again\n", destPath)
+
+		return existing, nil
+	}
+
+	merged, conflicts := diff.Merge3(base, existing, content, mergeLabels)
+	if conflicts > 0 {
+		inst.conflicts = append(inst.conflicts, destPath)
+		fmt.Fprintf(inst.out, "Conflicts between your changes and the new rules in %s; resolve the conflict markers\n", destPath)
+	} else {
+		fmt.Fprintf(inst.out, "Merged your changes into %s\n", destPath)
+	}
+
+	return merged, nil
+}
+
+// recordInstalled records the content installed to the destination as the base of the next merge.
+func (inst *installation) recordInstalled(destPath string, content []byte) error {
+	if inst.stateDir == "" {
+		return nil
+	}
+
+	installedPath, err := inst.installedPath(destPath)
+	if err != nil {
+		return err
+	}
+
+	if recorded, err := inst.fs.ReadFile(installedPath); err == nil && bytes.Equal(recorded, content) {
+		return nil
+	}
+
+	if err := inst.mkdirAll(filepath.Dir(installedPath)); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	if err := inst.writeFile(installedPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to record the installed content of '%s': %w", destPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_installation_writeOutputFile_merge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		edited        string
+		update        string
+		want          string
+		wantConflicts []string
+	}{
+		{name: "No local edits", edited: "A\nB\nC\n", update: "A\nB\nC new\n", want: "A\nB\nC new\n"},
+		{name: "Merge local edits", edited: "A mine\nB\nC\n", update: "A\nB\nC new\n", want: "A mine\nB\nC new\n"},
+		{name: "Keep local edits when the rules didn't change", edited: "A mine\nB\nC\n", update: "A\nB\nC\n", want: "A mine\nB\nC\n"},
+		{
+			name:          "Conflicting edits",
+			edited:        "A\nB\nC mine\n",
+			update:        "A\nB\nC new\n",
+			want:          "A\nB\n<<<<<<< current\nC mine\n||||||| last installed by airules\nC\n=======\nC new\n>>>>>>> airules\n",
+			wantConflicts: []string{"out.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newMemFS(map[string]string{})
+			require.NoError(t, newInstallation(fs, "state").writeOutputFile(editor.Output{Path: "out.md", Content: []byte("A\nB\nC\n")}, "test/default"))
+			require.NoError(t, fs.WriteFile("out.md", []byte(tt.edited), 0o644))
+
+			inst := newInstallation(fs, "state")
+			require.NoError(t, inst.writeOutputFile(editor.Output{Path: "out.md", Content: []byte(tt.update)}, "test/default"))
+
+			got, err := fs.ReadFile("out.md")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantConflicts, inst.conflicts)
+		})
+	}
+}
+
+func Test_installation_writeOutputFile_withoutState(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(map[string]string{"out.md": "hand-written\n"})
+	inst := newInstallation(fs, "state")
+	require.NoError(t, inst.writeOutputFile(editor.Output{Path: "out.md", Content: []byte("rules\n")}, "test/default"))
+
+	got, err := fs.ReadFile("out.md")
+	require.NoError(t, err)
+	assert.Equal(t, "rules\n", string(got), "Files airules didn't install before are replaced")
+	assert.Empty(t, inst.conflicts)
+}
+
+func Test_installation_writeOutputFile_unresolvedConflicts(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(map[string]string{})
+	require.NoError(t, newInstallation(fs, "state").writeOutputFile(editor.Output{Path: "out.md", Content: []byte("A\nB\n")}, "test/default"))
+	require.NoError(t, fs.WriteFile("out.md", []byte("A\nB mine\n"), 0o644))
+	require.NoError(t, newInstallation(fs, "state").writeOutputFile(editor.Output{Path: "out.md", Content: []byte("A\nB new\n")}, "test/default"))
+	conflicted, err := fs.ReadFile("out.md")
+	require.NoError(t, err)
+
+	// Installing again leaves the conflict markers alone and keeps reporting the conflicts
+	inst := newInstallation(fs, "state")
+	require.NoError(t, inst.writeOutputFile(editor.Output{Path: "out.md", Content: []byte("A\nB new\n")}, "test/default"))
+	got, err := fs.ReadFile("out.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(conflicted), string(got))
+	assert.Equal(t, []string{"out.md"}, inst.conflicts)
+
+	// Keeping the local edits resolves the conflicts
+	require.NoError(t, fs.WriteFile("out.md", []byte("A\nB mine\n"), 0o644))
+	inst = newInstallation(fs, "state")
+	require.NoError(t, inst.writeOutputFile(editor.Output{Path: "out.md", Content: []byte("A\nB new\n")}, "test/default"))
+	got, err = fs.ReadFile("out.md")
+	require.NoError(t, err)
+	assert.Equal(t, "A\nB mine\n", string(got))
+	assert.Empty(t, inst.conflicts)
+}
@@ -0,0 +1,27 @@
+package installer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+// renderRoo combines the rule files into Roo Code's rules directory for the rule-set key.
+// The default key installs into rules/, any other key is treated as a Roo mode slug and
+// installs into rules-<key>/ so the rules only apply in that mode.
+func renderRoo(rules editor.Rules) ([]editor.Output, error) {
+	destPath := rules.Destination
+	if rules.Key != defaultKey {
+		if !filepath.IsLocal(rules.Key) || strings.ContainsAny(rules.Key, `/\`) {
+			return nil, fmt.Errorf("invalid Roo mode '%s'", rules.Key)
+		}
+
+		rulesDir := filepath.Dir(destPath)
+		modeDir := filepath.Join(filepath.Dir(rulesDir), filepath.Base(rulesDir)+"-"+rules.Key)
+		destPath = filepath.Join(modeDir, filepath.Base(destPath))
+	}
+
+	return []editor.Output{{Path: destPath, Content: editor.Combine(rules.Files, editor.FormatMarkdown)}}, nil
+}
@@ -0,0 +1,45 @@
+package installer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_renderRoo(t *testing.T) {
+	t.Parallel()
+
+	rules := []editor.Rule{{Path: "templates/roo/local/rules.md", Content: []byte("rule\n")}}
+	destPath := filepath.Join(".roo", "rules", "airules.md")
+
+	tests := []struct {
+		name     string
+		key      string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "Default key installs into rules", key: "default", wantPath: filepath.Join(".roo", "rules", "airules.md")},
+		{name: "Mode key installs into rules-<mode>", key: "code", wantPath: filepath.Join(".roo", "rules-code", "airules.md")},
+		{name: "Key with a path separator", key: "../code", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderRoo(editor.Rules{Mode: editor.Local, Key: tt.key, Destination: destPath, Files: rules})
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, tt.wantPath, got[0].Path)
+			assert.Equal(t, "<!-- From rules.md -->\nrule\n", string(got[0].Content))
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// tempSuffix marks the temporary file that new content is written to before it's renamed over the destination.
+	tempSuffix = ".airules-tmp"
+	// originalSuffix marks a file that a transaction replaced, kept until the transaction is committed.
+	originalSuffix = ".airules-orig"
+)
+
+// transaction records the files an install replaces and the directories it creates,
+// so that they can all be restored if a later step fails.
+type transaction struct {
+	fs      FileSystem
+	entries []transactionEntry
+	seen    map[string]bool
+	// onCommit are called when the transaction is committed.
+	onCommit []func()
+}
+
+// transactionEntry is a file written or a directory created in a transaction.
+type transactionEntry struct {
+	path string
+	// original is where the file that was replaced was moved; it's empty when the file was created.
+	original string
+	// dir is set when the entry is a directory that the transaction created.
+	dir bool
+}
+
+// newTransaction returns a transaction that writes through fs.
+func newTransaction(fs FileSystem) *transaction {
+	return &transaction{fs: fs, seen: make(map[string]bool)}
+}
+
+// writeFile writes the file through a temporary file. The first time a file is written in the transaction,
+// the file it replaces is moved aside so that rollback can restore it.
+func (tx *transaction) writeFile(path string, data []byte, perm os.FileMode) error {
+	path = filepath.Clean(path)
+	tempPath := path + tempSuffix
+	if err := tx.fs.WriteFile(tempPath, data, perm); err != nil {
+		return err
+	}
+
+	if !tx.seen[path] {
+		entry := transactionEntry{path: path}
+		_, err := tx.fs.Stat(path)
+		switch {
+		case err == nil:
+			entry.original = path + originalSuffix
+			if err := tx.fs.Rename(path, entry.original); err != nil {
+				return errors.Join(err, tx.fs.Remove(tempPath))
+			}
+		case !os.IsNotExist(err):
+			return errors.Join(err, tx.fs.Remove(tempPath))
+		}
+		tx.seen[path] = true
+		tx.entries = append(tx.entries, entry)
+	}
+
+	if err := tx.fs.Rename(tempPath, path); err != nil {
+		return errors.Join(err, tx.fs.Remove(tempPath))
+	}
+
+	return nil
+}
+
+// mkdirAll creates the directory and any missing parents, recording the ones it creates so that rollback can
+// remove them.
+func (tx *transaction) mkdirAll(path string, perm os.FileMode) error {
+	path = filepath.Clean(path)
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		_, err := tx.fs.Stat(dir)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if err := tx.fs.MkdirAll(path, perm); err != nil {
+		return err
+	}
+
+	// Parents are recorded first so that rollback removes them after their children
+	for i := len(missing) - 1; i >= 0; i-- {
+		tx.entries = append(tx.entries, transactionEntry{path: missing[i], dir: true})
+	}
+
+	return nil
+}
+
+// commit removes the files that the transaction replaced.
+func (tx *transaction) commit() error {
+	var errs []error
+	for _, entry := range tx.entries {
+		if entry.original == "" {
+			continue
+		}
+		if err := tx.fs.Remove(entry.original); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove '%s': %w", entry.original, err))
+		}
+	}
+	tx.entries = nil
+
+	for _, fn := range tx.onCommit {
+		fn()
+	}
+	tx.onCommit = nil
+
+	return errors.Join(errs...)
+}
+
+// rollback restores the files that the transaction replaced and removes the files and empty directories it created,
+// latest first.
+func (tx *transaction) rollback() error {
+	var errs []error
+	for i := len(tx.entries) - 1; i >= 0; i-- {
+		entry := tx.entries[i]
+		if entry.dir {
+			if err := tx.removeEmptyDir(entry.path); err != nil {
+				errs = append(errs, err)
+			}
+
+			continue
+		}
+
+		if err := tx.fs.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove '%s': %w", entry.path, err))
+
+			continue
+		}
+		if entry.original == "" {
+			continue
+		}
+		if err := tx.fs.Rename(entry.original, entry.path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore '%s' from '%s': %w", entry.path, entry.original, err))
+		}
+	}
+	tx.entries = nil
+	tx.onCommit = nil
+
+	return errors.Join(errs...)
+}
+
+// removeEmptyDir removes a directory created by the transaction unless something else was put in it.
+func (tx *transaction) removeEmptyDir(path string) error {
+	entries, err := tx.fs.ReadDir(path)
+	if os.IsNotExist(err) || len(entries) > 0 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read directory '%s': %w", path, err)
+	}
+
+	if err := tx.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove directory '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// afterCommit calls fn once the installation's transaction is committed, or right away if it has none.
+func (inst *installation) afterCommit(fn func()) {
+	if inst.tx != nil {
+		inst.tx.onCommit = append(inst.tx.onCommit, fn)
+
+		return
+	}
+
+	fn()
+}
+
+// mkdirAll creates a directory for the installation, as part of its transaction if it has one.
+func (inst *installation) mkdirAll(path string) error {
+	if inst.tx != nil {
+		return inst.tx.mkdirAll(path, 0o755)
+	}
+
+	return inst.fs.MkdirAll(path, 0o755)
+}
+
+// writeFile writes a file installed or recorded by the installation, as part of its transaction if it has one.
+func (inst *installation) writeFile(path string, data []byte, perm os.FileMode) error {
+	if inst.tx != nil {
+		return inst.tx.writeFile(path, data, perm)
+	}
+
+	tempPath := filepath.Clean(path) + tempSuffix
+	if err := inst.fs.WriteFile(tempPath, data, perm); err != nil {
+		return err
+	}
+	if err := inst.fs.Rename(tempPath, path); err != nil {
+		return errors.Join(err, inst.fs.Remove(tempPath))
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingFS is a memFS that fails to write files in a directory.
+type failingFS struct {
+	*memFS
+	failDir string
+}
+
+func (f *failingFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	if filepath.Dir(filepath.Clean(path)) == f.failDir {
+		return &os.PathError{Op: "open", Path: path, Err: os.ErrPermission}
+	}
+
+	return f.memFS.WriteFile(path, data, perm)
+}
+
+func Test_transaction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rollback bool
+		want     map[string]string
+	}{
+		{
+			name: "Commit keeps the new files",
+			want: map[string]string{"local/rules.md": "new local", "global/rules.md": "new global 2"},
+		},
+		{
+			name:     "Rollback restores the replaced files and removes the created ones",
+			rollback: true,
+			want:     map[string]string{"local/rules.md": "old local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newMemFS(map[string]string{"local/rules.md": "old local"})
+			tx := newTransaction(fs)
+			require.NoError(t, tx.writeFile("local/rules.md", []byte("new local"), 0o644))
+			require.NoError(t, tx.writeFile("global/rules.md", []byte("new global 1"), 0o644))
+			require.NoError(t, tx.writeFile("global/rules.md", []byte("new global 2"), 0o644))
+
+			if tt.rollback {
+				require.NoError(t, tx.rollback())
+			} else {
+				require.NoError(t, tx.commit())
+			}
+
+			assert.Equal(t, newMemFS(tt.want).files, fs.files)
+		})
+	}
+}
+
+func Test_transaction_mkdirAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rollback bool
+		wantDirs []string
+	}{
+		{
+			name:     "Commit keeps the created directories",
+			wantDirs: []string{"existing", "existing/new", "rules", "rules/code", "rules/code/nested"},
+		},
+		{
+			name:     "Rollback removes the created directories",
+			rollback: true,
+			wantDirs: []string{"existing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(root, "existing"), 0o755))
+			tx := newTransaction(NewOsFS())
+			require.NoError(t, tx.mkdirAll(filepath.Join(root, "rules", "code", "nested"), 0o755))
+			require.NoError(t, tx.writeFile(filepath.Join(root, "rules", "code", "nested", "rules.md"), []byte("rules"), 0o644))
+			require.NoError(t, tx.mkdirAll(filepath.Join(root, "existing", "new"), 0o755))
+
+			if tt.rollback {
+				require.NoError(t, tx.rollback())
+			} else {
+				require.NoError(t, tx.commit())
+			}
+
+			var dirs []string
+			require.NoError(t, filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+				if err != nil || path == root || !d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(root, path)
+				dirs = append(dirs, filepath.ToSlash(rel))
+
+				return err
+			}))
+			assert.Equal(t, tt.wantDirs, dirs)
+		})
+	}
+}
+
+func Test_installation_writeOutputFile_rollback(t *testing.T) {
+	t.Parallel()
+
+	fs := &failingFS{
+		memFS:   newMemFS(map[string]string{"local/rules.md": "old local", "global/rules.md": "old global"}),
+		failDir: "global",
+	}
+	inst := newInstallation(fs, "")
+	inst.backupDir = "/backups"
+	inst.tx = newTransaction(fs)
+
+	require.NoError(t, inst.writeOutputFile(editor.Output{Path: "local/rules.md", Content: []byte("new local")}, "test/default"))
+	err := inst.writeOutputFile(editor.Output{Path: "global/rules.md", Content: []byte("new global")}, "test/default")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, os.ErrPermission))
+
+	backups, err := inst.fileBackups("local/rules.md")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	require.NoError(t, inst.tx.rollback())
+
+	for path, want := range map[string]string{"local/rules.md": "old local", "global/rules.md": "old global"} {
+		got, err := fs.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	}
+	assert.NotContains(t, fs.files, filepath.Clean("local/rules.md"+originalSuffix))
+	assert.NotContains(t, fs.files, filepath.Clean("local/rules.md"+tempSuffix))
+	for path := range fs.files {
+		assert.False(t, strings.HasPrefix(path, "/backups"), "backup '%s' should be removed", path)
+	}
+}
@@ -0,0 +1,248 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/manifest"
+)
+
+// UninstallOptions are the options for uninstalling rules.
+type UninstallOptions struct {
+	// Force removes files that were modified since they were installed.
+	Force bool
+}
+
+// Uninstall removes the rules installed for the editor, as recorded in the manifests.
+// Managed blocks are removed from the files that hold them, changes patched into files such as config files are
+// reverted, other files are removed and replaced by the backup of the file they replaced when they were first
+// installed, and local directories left empty are removed.
+func Uninstall(name string, installType InstallType, opts UninstallOptions) error {
+	if name == "" {
+		return fmt.Errorf("editor name cannot be empty")
+	}
+
+	var modes []editor.Mode
+	switch installType {
+	case Local:
+		modes = []editor.Mode{editor.Local}
+	case Global:
+		modes = []editor.Mode{editor.Global}
+	case All:
+		modes = []editor.Mode{editor.Local, editor.Global}
+	default:
+		return fmt.Errorf("invalid install type: %s", installType)
+	}
+
+	inst := newInstallation(NewOsFS(), "")
+	if err := inst.useConfigDirs(); err != nil {
+		return err
+	}
+
+	return inst.uninstall(name, modes, opts)
+}
+
+// uninstall removes the files recorded for the editor in the manifests of the modes.
+func (inst *installation) uninstall(name string, modes []editor.Mode, opts UninstallOptions) error {
+	found := false
+	for _, mode := range modes {
+		path := inst.manifestPath(mode)
+		m, err := inst.readManifest(path)
+		if err != nil {
+			return err
+		}
+
+		kept := make([]manifest.File, 0, len(m.Files))
+		for _, file := range m.Files {
+			if file.Editor != name || file.Mode != mode {
+				kept = append(kept, file)
+
+				continue
+			}
+			found = true
+
+			removed, err := inst.uninstallFile(file, opts)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				kept = append(kept, file)
+			}
+		}
+
+		m.Files = kept
+		if err := inst.writeManifest(path, m); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no installed rules for editor '%s' are recorded in the manifests", name)
+	}
+
+	return nil
+}
+
+// uninstallFile removes an installed file or its managed block, and reports false if it was kept.
+func (inst *installation) uninstallFile(file manifest.File, opts UninstallOptions) (bool, error) {
+	path := filepath.FromSlash(file.Path)
+	content, err := inst.fs.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(inst.out, "Skipped %s: it no longer exists\n", path)
+
+		return true, inst.forgetInstalled(path)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	if file.Patch != "" {
+		return true, inst.revertPatch(path, content, file)
+	}
+
+	if file.Block != "" {
+		rest, found, err := removeManagedBlock(content, file.Block)
+		if err != nil {
+			return false, fmt.Errorf("failed to remove managed block from '%s': %w", path, err)
+		}
+		if !found {
+			fmt.Fprintf(inst.out, "Skipped %s: it has no managed block '%s'\n", path, file.Block)
+
+			return true, inst.forgetInstalled(path)
+		}
+
+		// Files that only hold the managed block are removed as a whole
+		if len(bytes.TrimSpace(rest)) > 0 {
+			if err := inst.writeFile(path, rest, 0o644); err != nil {
+				return false, fmt.Errorf("failed to write '%s': %w", path, err)
+			}
+			fmt.Fprintf(inst.out, "Removed managed block '%s' from %s\n", file.Block, path)
+
+			return true, inst.forgetInstalled(path)
+		}
+	} else if !opts.Force && manifest.Hash(content) != file.Hash {
+		fmt.Fprintf(inst.out, "Kept %s: it was modified since it was installed (use --force to remove it)\n", path)
+
+		return false, nil
+	}
+
+	if err := inst.removeFile(path, file.Original); err != nil {
+		return false, err
+	}
+
+	return true, inst.forgetInstalled(path)
+}
+
+// patchReverters revert the patches recorded in manifests by their kind, given the patched content and the argument
+// of the patch. They also report whether the file has nothing left in it.
+var patchReverters = map[string]func(content []byte, arg string) ([]byte, bool, error){
+	aiderReadPatch:         removeAiderReadFile,
+	geminiContextFilePatch: unsetGeminiContextFileName,
+}
+
+// revertPatch reverts the patch recorded for a file and keeps the rest of the file, which is only removed when
+// the patch created it and nothing else is left in it.
+func (inst *installation) revertPatch(path string, content []byte, file manifest.File) error {
+	kind, arg, _ := strings.Cut(file.Patch, ":")
+	revert, ok := patchReverters[kind]
+	if !ok {
+		return fmt.Errorf("unknown patch '%s' recorded for '%s'", file.Patch, path)
+	}
+
+	reverted, empty, err := revert(content, arg)
+	if err != nil {
+		return fmt.Errorf("failed to revert the changes to '%s': %w", path, err)
+	}
+
+	switch {
+	case empty && file.Created:
+		if err := inst.removeFile(path, ""); err != nil {
+			return err
+		}
+	case bytes.Equal(reverted, content):
+		fmt.Fprintf(inst.out, "Skipped %s: the changes airules made were already removed\n", path)
+	default:
+		if err := inst.writeFile(path, reverted, 0o644); err != nil {
+			return fmt.Errorf("failed to write '%s': %w", path, err)
+		}
+		fmt.Fprintf(inst.out, "Reverted the changes airules made to %s\n", path)
+	}
+
+	return inst.forgetInstalled(path)
+}
+
+// removeFile removes an installed file and restores the backup of the file it replaced when it was first installed,
+// if there is one. Otherwise the local directories left empty are removed.
+func (inst *installation) removeFile(path, original string) error {
+	if err := inst.fs.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove '%s': %w", path, err)
+	}
+	fmt.Fprintf(inst.out, "Removed %s\n", path)
+
+	if original == "" {
+		return inst.removeEmptyDirs(filepath.Dir(path))
+	}
+
+	// Backups may be on another file system, so they're copied back rather than renamed
+	backupPath := inst.resolveRecordedPath(original)
+	content, err := inst.fs.ReadFile(backupPath)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(inst.out, "Skipped restoring %s: its backup %s no longer exists\n", path, backupPath)
+
+		return inst.removeEmptyDirs(filepath.Dir(path))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read backup '%s': %w", backupPath, err)
+	}
+	if err := inst.writeFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to restore backup '%s': %w", backupPath, err)
+	}
+	if err := inst.fs.Remove(backupPath); err != nil {
+		return fmt.Errorf("failed to remove restored backup '%s': %w", backupPath, err)
+	}
+	fmt.Fprintf(inst.out, "Restored %s from %s\n", path, backupPath)
+
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents while they're empty. Only directories inside the working directory
+// are removed, since directories elsewhere may belong to the editor.
+func (inst *installation) removeEmptyDirs(dir string) error {
+	for ; dir != "." && filepath.IsLocal(dir); dir = filepath.Dir(dir) {
+		entries, err := inst.fs.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory '%s': %w", dir, err)
+		}
+		if len(entries) > 0 {
+			return nil
+		}
+
+		if err := inst.fs.Remove(dir); err != nil {
+			return fmt.Errorf("failed to remove directory '%s': %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// forgetInstalled removes the last-installed content recorded for the destination.
+func (inst *installation) forgetInstalled(destPath string) error {
+	if inst.stateDir == "" {
+		return nil
+	}
+
+	installedPath, err := inst.installedPath(destPath)
+	if err != nil {
+		return err
+	}
+
+	if err := inst.fs.Remove(installedPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the installed content of '%s': %w", destPath, err)
+	}
+
+	return nil
+}
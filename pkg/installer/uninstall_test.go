@@ -0,0 +1,207 @@
+package installer
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/hashiiiii/airules/pkg/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_installation_uninstall(t *testing.T) {
+	t.Parallel()
+
+	const block = "<!-- airules:begin claude/default -->\nrules\n<!-- airules:end claude/default -->\n"
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		entries   []manifest.File
+		opts      UninstallOptions
+		want      map[string]string
+		wantKept  []manifest.File
+		wantError string
+	}{
+		{
+			name:    "Remove the managed block and keep the rest of the file",
+			files:   map[string]string{"CLAUDE.md": "# Notes\n\n" + block},
+			entries: []manifest.File{{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Block: "claude/default"}},
+			want:    map[string]string{"CLAUDE.md": "# Notes\n"},
+		},
+		{
+			name:    "Remove a file that only holds the managed block",
+			files:   map[string]string{"CLAUDE.md": block},
+			entries: []manifest.File{{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Block: "claude/default"}},
+			want:    map[string]string{},
+		},
+		{
+			name:  "Remove the managed blocks of every rule set",
+			files: map[string]string{"CLAUDE.md": "# Notes\n\n" + block + "\n<!-- airules:begin claude/backend -->\nmore\n<!-- airules:end claude/backend -->\n"},
+			entries: []manifest.File{
+				{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "backend", Block: "claude/backend"},
+				{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "default", Block: "claude/default"},
+			},
+			want: map[string]string{"CLAUDE.md": "# Notes\n"},
+		},
+		{
+			name:    "Remove the file and the directories left empty",
+			files:   map[string]string{".cursor/rules/project_rules.mdc": "rules"},
+			entries: []manifest.File{{Path: ".cursor/rules/project_rules.mdc", Editor: "cursor", Mode: editor.Local, Hash: manifest.Hash([]byte("rules"))}},
+			want:    map[string]string{},
+		},
+		{
+			name: "Restore the backup of the file replaced by the first install",
+			files: map[string]string{
+				".clinerules/airules.md":                        "rules",
+				".clinerules/airules.md.backup_20240101_120000": "mine",
+				".clinerules/airules.md.backup_20240102_120000": "older rules",
+			},
+			entries: []manifest.File{{
+				Path:     ".clinerules/airules.md",
+				Editor:   "cline",
+				Mode:     editor.Local,
+				Hash:     manifest.Hash([]byte("rules")),
+				Original: ".clinerules/airules.md.backup_20240101_120000",
+			}},
+			want: map[string]string{
+				".clinerules/airules.md":                        "mine",
+				".clinerules/airules.md.backup_20240102_120000": "older rules",
+			},
+		},
+		{
+			name: "Don't restore backups of installed rules",
+			files: map[string]string{
+				".clinerules/airules.md":                        "rules",
+				".clinerules/airules.md.backup_20240102_120000": "older rules",
+			},
+			entries: []manifest.File{{Path: ".clinerules/airules.md", Editor: "cline", Mode: editor.Local, Hash: manifest.Hash([]byte("rules"))}},
+			want:    map[string]string{".clinerules/airules.md.backup_20240102_120000": "older rules"},
+		},
+		{
+			name:     "Keep a modified file",
+			files:    map[string]string{".windsurfrules": "edited"},
+			entries:  []manifest.File{{Path: ".windsurfrules", Editor: "windsurf", Mode: editor.Local, Hash: manifest.Hash([]byte("rules"))}},
+			want:     map[string]string{".windsurfrules": "edited"},
+			wantKept: []manifest.File{{Path: ".windsurfrules", Editor: "windsurf", Mode: editor.Local, Hash: manifest.Hash([]byte("rules"))}},
+		},
+		{
+			name:    "Remove a modified file with force",
+			files:   map[string]string{".windsurfrules": "edited"},
+			entries: []manifest.File{{Path: ".windsurfrules", Editor: "windsurf", Mode: editor.Local, Hash: manifest.Hash([]byte("rules"))}},
+			opts:    UninstallOptions{Force: true},
+			want:    map[string]string{},
+		},
+		{
+			name:  "Revert a patch and keep later edits of the file",
+			files: map[string]string{".aider.conf.yml": "model: sonnet\nread:\n  - CONVENTIONS.md\nauto-commits: false\n"},
+			entries: []manifest.File{
+				{Path: ".aider.conf.yml", Editor: "aider", Mode: editor.Local, Hash: "sha256:00", Patch: "aider-read:CONVENTIONS.md"},
+			},
+			opts: UninstallOptions{Force: true},
+			want: map[string]string{".aider.conf.yml": "model: sonnet\nauto-commits: false\n"},
+		},
+		{
+			name:  "Remove a file that a patch created when nothing else is left in it",
+			files: map[string]string{".gemini/settings.json": "{\n  \"contextFileName\": \"AGENTS.md\"\n}\n"},
+			entries: []manifest.File{
+				{Path: ".gemini/settings.json", Editor: "gemini", Mode: editor.Local, Patch: "gemini-context-file:AGENTS.md", Created: true},
+			},
+			want: map[string]string{},
+		},
+		{
+			name:  "Keep a patched file that existed before the first install",
+			files: map[string]string{".aider.conf.yml": "read:\n  - CONVENTIONS.md\n"},
+			entries: []manifest.File{
+				{Path: ".aider.conf.yml", Editor: "aider", Mode: editor.Local, Patch: "aider-read:CONVENTIONS.md"},
+			},
+			want: map[string]string{".aider.conf.yml": "{}\n"},
+		},
+		{
+			name:    "Skip a file that no longer exists",
+			files:   map[string]string{},
+			entries: []manifest.File{{Path: ".windsurfrules", Editor: "windsurf", Mode: editor.Local}},
+			want:    map[string]string{},
+		},
+		{
+			name:      "Nothing installed for the editor",
+			files:     map[string]string{},
+			entries:   []manifest.File{{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Global}},
+			wantError: "no installed rules for editor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := newMemFS(tt.files)
+			data, err := (&manifest.Manifest{Version: manifest.Version, Files: tt.entries}).Marshal()
+			require.NoError(t, err)
+			fs.files[manifest.LocalFileName] = data
+
+			inst := newInstallation(fs, "")
+			inst.out = io.Discard
+			err = inst.uninstall(tt.entries[0].Editor, []editor.Mode{editor.Local}, tt.opts)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+
+				return
+			}
+			require.NoError(t, err)
+
+			lock, hasLock := fs.files[manifest.LocalFileName]
+			delete(fs.files, manifest.LocalFileName)
+			assert.Equal(t, newMemFS(tt.want).files, fs.files)
+
+			if tt.wantKept == nil {
+				assert.False(t, hasLock, "the empty manifest should be removed")
+
+				return
+			}
+			m, err := manifest.Parse(lock)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKept, m.Files)
+		})
+	}
+}
+
+func Test_installation_uninstall_reinstalled(t *testing.T) {
+	t.Parallel()
+
+	fs := newMemFS(map[string]string{"/project/rules.md": "mine\n", "/config/templates/rules.md": "first\n"})
+	config := EditorConfig{Name: "test", LocalPath: "/project", LocalFileName: "rules.md", Format: editor.FormatPlain}
+	e := newFileEditor("test", func() (EditorConfig, error) { return config, nil })
+	set := ruleSet{Mode: editor.Local, Key: "default", RulePaths: []string{"/config/templates/rules.md"}}
+
+	newTestInstallation := func() *installation {
+		inst := newInstallation(fs, "")
+		inst.out = io.Discard
+		inst.configDir, inst.backupDir, inst.projectDir = "/config", "/config/backups", "/project"
+
+		return inst
+	}
+
+	// Install, then reinstall with changed rules, so that the latest backup holds the first install's rules
+	for _, rules := range []string{"first\n", "second\n"} {
+		fs.files["/config/templates/rules.md"] = []byte(rules)
+		inst := newTestInstallation()
+		require.NoError(t, inst.installRuleSet(e, set))
+		require.NoError(t, inst.updateManifests("test", "default", []editor.Mode{editor.Local}))
+	}
+
+	m, err := manifest.Parse(fs.files[manifest.LocalFileName])
+	require.NoError(t, err)
+	require.Len(t, m.Files, 1)
+	assert.True(t, strings.HasPrefix(m.Files[0].Original, "backups/"), "original backup '%s' should be in the config directory", m.Files[0].Original)
+
+	require.NoError(t, newTestInstallation().uninstall("test", []editor.Mode{editor.Local}, UninstallOptions{}))
+
+	got, err := fs.ReadFile("/project/rules.md")
+	require.NoError(t, err)
+	assert.Equal(t, "mine\n", string(got))
+	assert.NotContains(t, fs.files, manifest.LocalFileName)
+}
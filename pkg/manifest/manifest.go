@@ -0,0 +1,128 @@
+// Package manifest records the files that airules installed.
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+const (
+	// Version is the version of the manifest format.
+	Version = 1
+	// LocalFileName is the name of the manifest of local installs, written to the project directory.
+	LocalFileName = ".airules.lock"
+	// GlobalFileName is the name of the manifest of global installs, written to the state directory.
+	GlobalFileName = "global.lock"
+)
+
+// header is written at the top of manifests.
+const header = "# This file is generated by airules. Do not edit it by hand.\n\n"
+
+// Manifest lists the files installed by airules.
+type Manifest struct {
+	Version int `toml:"version"`
+	// AirulesVersion is the version of airules that last wrote the manifest.
+	AirulesVersion string `toml:"airules_version,omitempty"`
+	Files          []File `toml:"file,omitempty"`
+}
+
+// File is an installed destination file.
+type File struct {
+	Path   string      `toml:"path"`
+	Editor string      `toml:"editor"`
+	Mode   editor.Mode `toml:"mode"`
+	Key    string      `toml:"key"`
+	// Sources are the rule files the destination was rendered from, relative to the config directory when they're in it.
+	Sources []string `toml:"sources"`
+	// TemplateVersion identifies the content of the sources.
+	TemplateVersion string `toml:"template_version"`
+	// Hash is the hash of the content written to the destination.
+	Hash string `toml:"hash"`
+	// Block is the ID of the managed block airules owns when the rest of the file is left to the user.
+	Block string `toml:"block,omitempty"`
+	// Original is the backup of the file that airules replaced when it first installed the destination,
+	// relative to the config directory when it's in it. It's restored when the destination is uninstalled.
+	Original string `toml:"original,omitempty"`
+	// Patch identifies the change airules made to a file that it doesn't own, such as an entry it added to a config
+	// file. Uninstall reverts the change and keeps the rest of the file.
+	Patch string `toml:"patch,omitempty"`
+	// Created reports whether the patch created the file, which uninstall removes when nothing else is left in it.
+	Created bool `toml:"created,omitempty"`
+}
+
+// Parse parses a manifest. Empty data is an empty manifest.
+func Parse(data []byte) (*Manifest, error) {
+	m := &Manifest{Version: Version}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return m, nil
+	}
+
+	if _, err := toml.Decode(string(data), m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("manifest version %d is newer than the supported version %d; upgrade airules", m.Version, Version)
+	}
+
+	return m, nil
+}
+
+// Marshal encodes the manifest.
+func (m *Manifest) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	if err := toml.NewEncoder(&buf).Encode(m); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Replace replaces the files installed for the editor, mode and rule-set key with files. Other entries for the same
+// paths are dropped too, except for entries of other editors' managed blocks when the file holds a managed block.
+// Files are kept sorted by path and block so that the manifest is stable.
+func (m *Manifest) Replace(editorName string, mode editor.Mode, key string, files []File) {
+	kept := m.Files[:0]
+	for _, f := range m.Files {
+		if f.Editor == editorName && f.Mode == mode && f.Key == key {
+			continue
+		}
+		if slices.ContainsFunc(files, f.overlaps) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	m.Files = append(kept, files...)
+
+	sort.SliceStable(m.Files, func(i, j int) bool {
+		if m.Files[i].Path != m.Files[j].Path {
+			return m.Files[i].Path < m.Files[j].Path
+		}
+
+		return m.Files[i].Block < m.Files[j].Block
+	})
+}
+
+// overlaps reports whether f and other record the same content: the same path when either owns the whole file,
+// or a managed block of the same editor, which replaces the editor's blocks of other rule sets.
+func (f File) overlaps(other File) bool {
+	if f.Path != other.Path {
+		return false
+	}
+
+	return f.Block == "" || other.Block == "" || f.Editor == other.Editor
+}
+
+// Hash returns the hash of content as recorded in manifests.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    *Manifest
+		wantErr string
+	}{
+		{name: "Empty", data: "", want: &Manifest{Version: Version}},
+		{
+			name: "Files",
+			data: "version = 1\n[[file]]\npath = \"CLAUDE.md\"\neditor = \"claude\"\nmode = \"local\"\nkey = \"default\"\n" +
+				"sources = [\"templates/claude/local/CLAUDE.md\"]\ntemplate_version = \"abc\"\nhash = \"sha256:00\"\n",
+			want: &Manifest{Version: 1, Files: []File{{
+				Path:            "CLAUDE.md",
+				Editor:          "claude",
+				Mode:            editor.Local,
+				Key:             "default",
+				Sources:         []string{"templates/claude/local/CLAUDE.md"},
+				TemplateVersion: "abc",
+				Hash:            "sha256:00",
+			}}},
+		},
+		{name: "Newer version", data: "version = 2\n", wantErr: "manifest version 2 is newer"},
+		{name: "Invalid", data: "version = \n", wantErr: "failed to parse manifest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Manifest_Marshal(t *testing.T) {
+	t.Parallel()
+
+	m := &Manifest{Version: Version, AirulesVersion: "v1.0.0", Files: []File{{
+		Path:    "CLAUDE.md",
+		Editor:  "claude",
+		Mode:    editor.Local,
+		Key:     "default",
+		Sources: []string{"templates/claude/local/CLAUDE.md"},
+		Hash:    Hash([]byte("rules")),
+		Block:   "claude/default",
+	}}}
+
+	data, err := m.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), header)
+
+	got, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, m, got)
+}
+
+func Test_Manifest_Replace(t *testing.T) {
+	t.Parallel()
+
+	m := &Manifest{Version: Version, Files: []File{
+		{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "default", Block: "claude/default"},
+		{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "backend", Block: "claude/backend"},
+		{Path: "CLAUDE.md", Editor: "other", Mode: editor.Local, Key: "default", Block: "other/default"},
+		{Path: "old.md", Editor: "cursor", Mode: editor.Local, Key: "backend"},
+		{Path: "rules.md", Editor: "cursor", Mode: editor.Local, Key: "default"},
+		{Path: "shared.md", Editor: "agents", Mode: editor.Local, Key: "default"},
+		{Path: "/home/.cursor/rules.md", Editor: "cursor", Mode: editor.Global, Key: "backend"},
+	}}
+
+	m.Replace("cursor", editor.Local, "backend", []File{
+		{Path: "shared.md", Editor: "cursor", Mode: editor.Local, Key: "backend"},
+		{Path: "a.md", Editor: "cursor", Mode: editor.Local, Key: "backend"},
+	})
+	m.Replace("claude", editor.Local, "backend", []File{
+		{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "backend", Block: "claude/backend", Hash: "sha256:01"},
+	})
+
+	want := []File{
+		{Path: "/home/.cursor/rules.md", Editor: "cursor", Mode: editor.Global, Key: "backend"},
+		{Path: "CLAUDE.md", Editor: "claude", Mode: editor.Local, Key: "backend", Block: "claude/backend", Hash: "sha256:01"},
+		{Path: "CLAUDE.md", Editor: "other", Mode: editor.Local, Key: "default", Block: "other/default"},
+		{Path: "a.md", Editor: "cursor", Mode: editor.Local, Key: "backend"},
+		{Path: "rules.md", Editor: "cursor", Mode: editor.Local, Key: "default"},
+		{Path: "shared.md", Editor: "cursor", Mode: editor.Local, Key: "backend"},
+	}
+	assert.Equal(t, want, m.Files)
+}
+
+func Test_Hash(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Hash(nil))
+}
@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashiiiii/airules/pkg/config"
+)
+
+// ExecutablePrefix is the file name prefix of plugin executables.
+const ExecutablePrefix = "airules-editor-"
+
+// GetPluginDir returns the directory searched for plugins before PATH.
+func GetPluginDir() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "plugins"), nil
+}
+
+// Discover finds plugin executables in the plugins directory and on PATH.
+// When several executables have the same name, the one in the plugins directory wins,
+// then the one that comes first on PATH.
+func Discover() []*Plugin {
+	var dirs []string
+	if pluginDir, err := GetPluginDir(); err == nil {
+		dirs = append(dirs, pluginDir)
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+
+	return discoverIn(dirs)
+}
+
+// discoverIn finds plugin executables in dirs, in order of precedence.
+func discoverIn(dirs []string) []*Plugin {
+	seen := make(map[string]bool)
+	var plugins []*Plugin
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(entry)
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, New(name, filepath.Join(dir, entry.Name())))
+		}
+	}
+
+	return plugins
+}
+
+// pluginName returns the editor name of a plugin executable, and false if entry isn't one.
+func pluginName(entry os.DirEntry) (string, bool) {
+	fileName := entry.Name()
+	if entry.IsDir() || !strings.HasPrefix(fileName, ExecutablePrefix) {
+		return "", false
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		return "", false
+	}
+
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(fileName)
+		if !strings.EqualFold(ext, ".exe") {
+			return "", false
+		}
+		fileName = strings.TrimSuffix(fileName, ext)
+	} else if info.Mode()&0o111 == 0 {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(fileName, ExecutablePrefix)
+
+	return name, name != ""
+}
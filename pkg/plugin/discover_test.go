@@ -0,0 +1,43 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_discoverIn(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by extension on Windows")
+	}
+
+	pluginDir := t.TempDir()
+	pathDir := t.TempDir()
+	write := func(dir, name string, perm os.FileMode) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, perm))
+	}
+	write(pluginDir, "airules-editor-internal", 0o755)
+	write(pathDir, "airules-editor-internal", 0o755)
+	write(pathDir, "airules-editor-vim", 0o755)
+	write(pathDir, "airules-editor-notes", 0o644)
+	write(pathDir, "airules-editor-", 0o755)
+	write(pathDir, "airules", 0o755)
+	require.NoError(t, os.Mkdir(filepath.Join(pathDir, "airules-editor-dir"), 0o755))
+
+	plugins := discoverIn([]string{pluginDir, "", filepath.Join(pathDir, "missing"), pathDir})
+
+	got := make(map[string]string)
+	for _, p := range plugins {
+		got[p.Name()] = p.Path()
+	}
+	assert.Equal(t, map[string]string{
+		"internal": filepath.Join(pluginDir, "airules-editor-internal"),
+		"vim":      filepath.Join(pathDir, "airules-editor-vim"),
+	}, got)
+}
@@ -0,0 +1,223 @@
+// Package plugin runs external editor adapters named airules-editor-<name>.
+//
+// airules talks to a plugin by running it once per request, writing a single JSON request to its
+// stdin and reading a single JSON response from its stdout. Every request carries the protocol
+// version and a command:
+//
+//	{"version": 1, "command": "describe"}
+//	{"version": 1, "command": "validate"}
+//	{"version": 1, "command": "destinations", "mode": "local"}
+//	{"version": 1, "command": "render", "rules": {"mode": "local", "key": "default",
+//	  "destination": "RULES.md", "files": [{"path": "...", "content": "..."}], "options": {}}}
+//
+// Responses set the fields for the command, or error to report a failure:
+//
+//	{"supportsGlobal": true, "globalUnsupportedReason": ""}
+//	{}
+//	{"destinations": ["RULES.md"]}
+//	{"outputs": [{"path": "RULES.md", "content": "...", "managed": true}]}
+//	{"error": "something went wrong"}
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+)
+
+const (
+	// ProtocolVersion is the version of the JSON protocol sent with every request.
+	ProtocolVersion = 1
+	// requestTimeout bounds how long a plugin may take to answer a request.
+	requestTimeout = 30 * time.Second
+)
+
+const (
+	commandDescribe     = "describe"
+	commandValidate     = "validate"
+	commandDestinations = "destinations"
+	commandRender       = "render"
+)
+
+// request is a message sent to a plugin.
+type request struct {
+	Version int           `json:"version"`
+	Command string        `json:"command"`
+	Mode    editor.Mode   `json:"mode,omitempty"`
+	Rules   *rulesMessage `json:"rules,omitempty"`
+}
+
+// rulesMessage is the JSON form of editor.Rules.
+type rulesMessage struct {
+	Mode        editor.Mode       `json:"mode"`
+	Key         string            `json:"key"`
+	Destination string            `json:"destination"`
+	Files       []fileMessage     `json:"files"`
+	Options     map[string]string `json:"options,omitempty"`
+}
+
+// fileMessage is a file path and its content.
+type fileMessage struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	// Managed is only used in outputs, see editor.Output.
+	Managed bool `json:"managed,omitempty"`
+}
+
+// response is a message returned by a plugin.
+type response struct {
+	Error                   string        `json:"error,omitempty"`
+	SupportsGlobal          bool          `json:"supportsGlobal,omitempty"`
+	GlobalUnsupportedReason string        `json:"globalUnsupportedReason,omitempty"`
+	Destinations            []string      `json:"destinations,omitempty"`
+	Outputs                 []fileMessage `json:"outputs,omitempty"`
+}
+
+// Plugin is an editor implemented by an external executable.
+type Plugin struct {
+	name string
+	path string
+	// args are passed to the executable before the request is written.
+	args []string
+
+	describeOnce sync.Once
+	description  response
+	describeErr  error
+}
+
+// New returns a plugin for the executable at path.
+func New(name, path string) *Plugin {
+	return &Plugin{name: name, path: path}
+}
+
+// Name returns the editor name.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Path returns the path of the plugin executable.
+func (p *Plugin) Path() string {
+	return p.path
+}
+
+// SupportsGlobal reports whether the plugin supports global mode.
+func (p *Plugin) SupportsGlobal() bool {
+	description, err := p.describe()
+	if err != nil {
+		return false
+	}
+
+	return description.SupportsGlobal
+}
+
+// GlobalUnsupportedReason explains how to set global rules for the plugin's editor.
+func (p *Plugin) GlobalUnsupportedReason() string {
+	description, err := p.describe()
+	if err != nil {
+		return ""
+	}
+
+	return description.GlobalUnsupportedReason
+}
+
+// Destinations asks the plugin for the destination files of the mode.
+func (p *Plugin) Destinations(mode editor.Mode) ([]string, error) {
+	resp, err := p.call(request{Command: commandDestinations, Mode: mode})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Destinations, nil
+}
+
+// Render asks the plugin to render the rules.
+func (p *Plugin) Render(rules editor.Rules) ([]editor.Output, error) {
+	files := make([]fileMessage, 0, len(rules.Files))
+	for _, file := range rules.Files {
+		files = append(files, fileMessage{Path: file.Path, Content: string(file.Content)})
+	}
+
+	resp, err := p.call(request{
+		Command: commandRender,
+		Rules: &rulesMessage{
+			Mode:        rules.Mode,
+			Key:         rules.Key,
+			Destination: rules.Destination,
+			Files:       files,
+			Options:     rules.Options,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]editor.Output, 0, len(resp.Outputs))
+	for _, output := range resp.Outputs {
+		if output.Path == "" {
+			return nil, fmt.Errorf("plugin %s returned an output without a path", p.name)
+		}
+		outputs = append(outputs, editor.Output{Path: output.Path, Content: []byte(output.Content), Managed: output.Managed})
+	}
+
+	return outputs, nil
+}
+
+// Validate asks the plugin whether it can be used.
+func (p *Plugin) Validate() error {
+	_, err := p.call(request{Command: commandValidate})
+
+	return err
+}
+
+// describe asks the plugin to describe itself once and caches the answer.
+func (p *Plugin) describe() (response, error) {
+	p.describeOnce.Do(func() {
+		p.description, p.describeErr = p.call(request{Command: commandDescribe})
+	})
+
+	return p.description, p.describeErr
+}
+
+// call runs the plugin with the request and returns its response.
+func (p *Plugin) call(req request) (response, error) {
+	req.Version = ProtocolVersion
+	input, err := json.Marshal(req)
+	if err != nil {
+		return response{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	// #nosec G204 -- plugins are executables the user installed on PATH or in the plugins directory
+	cmd := exec.CommandContext(ctx, p.path, p.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return response{}, fmt.Errorf("plugin %s failed on %s: %w: %s", p.name, req.Command, err, msg)
+		}
+
+		return response{}, fmt.Errorf("plugin %s failed on %s: %w", p.name, req.Command, err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return response{}, fmt.Errorf("plugin %s returned an invalid response to %s: %w", p.name, req.Command, err)
+	}
+	if resp.Error != "" {
+		return response{}, fmt.Errorf("plugin %s: %s", p.name, resp.Error)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePluginArg makes the test binary act as a plugin.
+const fakePluginArg = "airules-fake-plugin"
+
+func TestMain(m *testing.M) {
+	if len(os.Args) > 2 && os.Args[1] == fakePluginArg {
+		os.Exit(runFakePlugin(os.Args[2]))
+	}
+	os.Exit(m.Run())
+}
+
+// runFakePlugin answers one request as a plugin that behaves according to behavior.
+func runFakePlugin(behavior string) int {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return 1
+	}
+
+	var resp response
+	switch behavior {
+	case "crash":
+		fmt.Fprintln(os.Stderr, "boom")
+
+		return 2
+	case "garbage":
+		fmt.Print("not json")
+
+		return 0
+	case "error":
+		resp.Error = "not configured"
+	default:
+		switch req.Command {
+		case commandDescribe:
+			resp.SupportsGlobal = true
+			resp.GlobalUnsupportedReason = "reason"
+		case commandDestinations:
+			resp.Destinations = []string{string(req.Mode) + ".md"}
+		case commandRender:
+			var contents []string
+			for _, file := range req.Rules.Files {
+				contents = append(contents, file.Content)
+			}
+			resp.Outputs = []fileMessage{{
+				Path:    req.Rules.Destination,
+				Content: req.Rules.Key + ":" + strings.Join(contents, "+"),
+				Managed: true,
+			}}
+		}
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+// newFakePlugin returns a plugin backed by the test binary.
+func newFakePlugin(t *testing.T, behavior string) *Plugin {
+	t.Helper()
+
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	p := New("fake", executable)
+	p.args = []string{fakePluginArg, behavior}
+
+	return p
+}
+
+func Test_Plugin(t *testing.T) {
+	t.Parallel()
+
+	p := newFakePlugin(t, "ok")
+
+	assert.Equal(t, "fake", p.Name())
+	assert.True(t, p.SupportsGlobal())
+	assert.Equal(t, "reason", p.GlobalUnsupportedReason())
+	require.NoError(t, p.Validate())
+
+	destinations, err := p.Destinations(editor.Global)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global.md"}, destinations)
+
+	outputs, err := p.Render(editor.Rules{
+		Mode:        editor.Local,
+		Key:         "backend",
+		Destination: "local.md",
+		Files:       []editor.Rule{{Path: "a.md", Content: []byte("A")}, {Path: "b.md", Content: []byte("B")}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []editor.Output{{Path: "local.md", Content: []byte("backend:A+B"), Managed: true}}, outputs)
+}
+
+func Test_Plugin_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		behavior string
+		wantErr  string
+	}{
+		{name: "Error response", behavior: "error", wantErr: "plugin fake: not configured"},
+		{name: "Non-zero exit", behavior: "crash", wantErr: "boom"},
+		{name: "Invalid response", behavior: "garbage", wantErr: "invalid response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newFakePlugin(t, tt.behavior)
+
+			err := p.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.False(t, p.SupportsGlobal())
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Detector returns the tags of the technologies it finds in a project root.
+type Detector func(root string) []string
+
+// detectors run in order, and the tags they return are listed in Info.Detected in the same order.
+var detectors = []Detector{
+	detectLanguages,
+	detectTools,
+	detectJavaScriptFrameworks,
+	detectPythonFrameworks,
+	detectRubyFrameworks,
+}
+
+// languageMarkers maps languages to files that mark a project written in them, in order of precedence.
+var languageMarkers = []struct {
+	language string
+	files    []string
+}{
+	{language: "go", files: []string{"go.mod"}},
+	{language: "rust", files: []string{"Cargo.toml"}},
+	{language: "typescript", files: []string{"tsconfig.json"}},
+	{language: "javascript", files: []string{"package.json"}},
+	{language: "python", files: []string{"pyproject.toml", "setup.py", "requirements.txt"}},
+	{language: "ruby", files: []string{"Gemfile"}},
+	{language: "java", files: []string{"pom.xml", "build.gradle", "build.gradle.kts"}},
+	{language: "php", files: []string{"composer.json"}},
+}
+
+// javaScriptFrameworks maps package.json dependencies to framework tags.
+var javaScriptFrameworks = []struct {
+	dependency string
+	tag        string
+}{
+	{dependency: "next", tag: "nextjs"},
+	{dependency: "react", tag: "react"},
+	{dependency: "vue", tag: "vue"},
+	{dependency: "svelte", tag: "svelte"},
+	{dependency: "@angular/core", tag: "angular"},
+	{dependency: "express", tag: "express"},
+}
+
+// pythonFrameworkPattern matches Python frameworks in requirements.txt or pyproject.toml.
+var pythonFrameworkPattern = regexp.MustCompile(`(?im)^[\s"']*(django|flask|fastapi)\b`)
+
+// detect runs the detectors on root and returns the tags they found without duplicates.
+func detect(root string) []string {
+	var tags []string
+	for _, detector := range detectors {
+		for _, tag := range detector(root) {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// detectLanguages returns the languages whose marker files exist in root.
+func detectLanguages(root string) []string {
+	var languages []string
+	for _, marker := range languageMarkers {
+		for _, file := range marker.files {
+			if exists(filepath.Join(root, file)) {
+				languages = append(languages, marker.language)
+
+				break
+			}
+		}
+	}
+
+	return languages
+}
+
+// detectTools detects Docker and Terraform.
+func detectTools(root string) []string {
+	var tags []string
+	for _, file := range []string{"Dockerfile", "compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"} {
+		if exists(filepath.Join(root, file)) {
+			tags = append(tags, "docker")
+
+			break
+		}
+	}
+
+	if matches, err := filepath.Glob(filepath.Join(root, "*.tf")); err == nil && len(matches) > 0 {
+		tags = append(tags, "terraform")
+	}
+
+	return tags
+}
+
+// detectJavaScriptFrameworks detects frameworks from the dependencies in package.json.
+func detectJavaScriptFrameworks(root string) []string {
+	content, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil
+	}
+
+	var tags []string
+	for _, framework := range javaScriptFrameworks {
+		_, inDependencies := manifest.Dependencies[framework.dependency]
+		_, inDevDependencies := manifest.DevDependencies[framework.dependency]
+		if inDependencies || inDevDependencies {
+			tags = append(tags, framework.tag)
+		}
+	}
+
+	return tags
+}
+
+// detectPythonFrameworks detects Django, Flask and FastAPI.
+func detectPythonFrameworks(root string) []string {
+	var tags []string
+	if exists(filepath.Join(root, "manage.py")) {
+		tags = append(tags, "django")
+	}
+
+	for _, file := range []string{"requirements.txt", "pyproject.toml"} {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		for _, match := range pythonFrameworkPattern.FindAllStringSubmatch(string(content), -1) {
+			tags = append(tags, strings.ToLower(match[1]))
+		}
+	}
+
+	return tags
+}
+
+// detectRubyFrameworks detects Rails.
+func detectRubyFrameworks(root string) []string {
+	if exists(filepath.Join(root, "config", "application.rb")) || exists(filepath.Join(root, "bin", "rails")) {
+		return []string{"rails"}
+	}
+
+	return nil
+}
+
+// exists reports whether a file exists at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
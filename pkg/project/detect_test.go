@@ -0,0 +1,67 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_detect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{name: "Empty project", files: map[string]string{}, want: nil},
+		{
+			name:  "Go service with Docker and Terraform",
+			files: map[string]string{"go.mod": "module example.com/svc\n", "Dockerfile": "FROM scratch\n", "infra.tf": ""},
+			want:  []string{"go", "docker", "terraform"},
+		},
+		{
+			name: "Next.js app in TypeScript",
+			files: map[string]string{
+				"package.json":  `{"dependencies": {"next": "15.0.0", "react": "19.0.0"}, "devDependencies": {"typescript": "5.0.0"}}`,
+				"tsconfig.json": "{}",
+			},
+			want: []string{"typescript", "javascript", "nextjs", "react"},
+		},
+		{name: "Invalid package.json", files: map[string]string{"package.json": "{"}, want: []string{"javascript"}},
+		{
+			name:  "Python frameworks",
+			files: map[string]string{"requirements.txt": "Django==5.0\nrequests\n", "pyproject.toml": "dependencies = [\n  \"fastapi>=0.100\",\n]\n"},
+			want:  []string{"python", "django", "fastapi"},
+		},
+		{name: "Django project", files: map[string]string{"manage.py": ""}, want: []string{"django"}},
+		{name: "Rails app", files: map[string]string{"Gemfile": "", "config/application.rb": ""}, want: []string{"ruby", "rails"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(root, filepath.FromSlash(name))
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+			}
+
+			assert.Equal(t, tt.want, detect(root))
+		})
+	}
+}
+
+func Test_Info_Has(t *testing.T) {
+	t.Parallel()
+
+	info := Info{Detected: []string{"go", "docker"}}
+
+	assert.True(t, info.Has("docker"))
+	assert.False(t, info.Has("terraform"))
+}
@@ -0,0 +1,115 @@
+// Package project describes the project that rules are installed into.
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Info describes a project.
+type Info struct {
+	// Name is the name of the project's root directory.
+	Name string
+	// Root is the repository root, or the project directory outside a git repository.
+	Root string
+	// Language is the primary language of the project, or empty if none was detected.
+	Language string
+	// Languages are the languages detected in the directory and then in the project root.
+	Languages []string
+	// Detected are the languages, tools and frameworks detected in the directory and in the project root,
+	// such as go, docker or react.
+	Detected []string
+	// GitRemote is the URL of the origin remote, or empty if there is none.
+	GitRemote string
+}
+
+// Has reports whether tag was detected in the project.
+func (i Info) Has(tag string) bool {
+	return slices.Contains(i.Detected, tag)
+}
+
+// Detect describes the project containing dir. Languages and tools are detected in dir, such as a service of
+// a monorepo, and in the project root, with those of dir first.
+func Detect(dir string) (Info, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return Info{}, err
+	}
+
+	root := findRoot(absDir)
+	info := Info{
+		Name:      filepath.Base(root),
+		Root:      root,
+		Languages: detectLanguages(absDir),
+		Detected:  detect(absDir),
+		GitRemote: readGitRemote(filepath.Join(root, ".git", "config")),
+	}
+	if root != absDir {
+		info.Languages = appendMissing(info.Languages, detectLanguages(root))
+		info.Detected = appendMissing(info.Detected, detect(root))
+	}
+	if len(info.Languages) > 0 {
+		info.Language = info.Languages[0]
+	}
+
+	return info, nil
+}
+
+// appendMissing appends the tags that aren't in tags yet.
+func appendMissing(tags, more []string) []string {
+	for _, tag := range more {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// findRoot returns the closest directory from dir upwards that contains .git, or dir if there is none.
+func findRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// readGitRemote returns the URL of the origin remote from a git config file.
+func readGitRemote(configPath string) string {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return ""
+	}
+
+	inOrigin := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,69 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Detect(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "airules")
+	sub := filepath.Join(root, "pkg", "config")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	gitConfig := "[core]\n\tbare = false\n[remote \"upstream\"]\n\turl = https://example.com/upstream.git\n" +
+		"[remote \"origin\"]\n\turl = git@github.com:hashiiiii/airules.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "config"), []byte(gitConfig), 0o644))
+	for _, file := range []string{"go.mod", "requirements.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, file), nil, 0o644))
+	}
+
+	info, err := Detect(sub)
+	require.NoError(t, err)
+	assert.Equal(t, Info{
+		Name:      "airules",
+		Root:      root,
+		Language:  "go",
+		Languages: []string{"go", "python"},
+		Detected:  []string{"go", "python"},
+		GitRemote: "git@github.com:hashiiiii/airules.git",
+	}, info)
+}
+
+func Test_Detect_monorepo(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "monorepo")
+	service := filepath.Join(root, "svc")
+	require.NoError(t, os.MkdirAll(service, 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "package.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Dockerfile"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(service, "go.mod"), nil, 0o644))
+
+	info, err := Detect(service)
+	require.NoError(t, err)
+	assert.Equal(t, root, info.Root)
+	assert.Equal(t, "go", info.Language, "The directory's language should come first")
+	assert.Equal(t, []string{"go", "javascript"}, info.Languages)
+	assert.True(t, info.Has("go"))
+	assert.True(t, info.Has("docker"), "Tags detected in the project root should be kept")
+}
+
+func Test_Detect_outsideRepository(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	info, err := Detect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, info.Root)
+	assert.Equal(t, filepath.Base(dir), info.Name)
+	assert.Empty(t, info.Language)
+	assert.Empty(t, info.GitRemote)
+}
@@ -0,0 +1,184 @@
+// Package render renders rule files as Go templates.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hashiiiii/airules/pkg/project"
+)
+
+// Data is the data available to rule templates.
+type Data struct {
+	Project project.Info
+	Editor  string
+	Mode    string
+	Key     string
+	// Vars are the variables from config.toml and --var flags.
+	Vars map[string]string
+}
+
+// File is a rule file to render.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Renderer renders rule files and the partials they include.
+type Renderer struct {
+	// IncludeDir is the directory that include paths are relative to.
+	IncludeDir string
+	// ReadFile reads included files; os.ReadFile is used when it's nil.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// Funcs returns the functions available to rule templates, except include.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"has":        func(elem string, elems []string) bool { return slices.Contains(elems, elem) },
+		"default":    defaultValue,
+		"quote":      func(s string) string { return fmt.Sprintf("%q", s) },
+		"indent":     indent,
+	}
+}
+
+// Render renders the content of the rule file at path as a template without include support.
+// Content without template actions is returned as is.
+func Render(path string, content []byte, data Data) ([]byte, error) {
+	return (&Renderer{}).Render(path, content, data)
+}
+
+// Render renders the content of the rule file at path as a template.
+// Content without template actions is returned as is.
+func (r *Renderer) Render(path string, content []byte, data Data) ([]byte, error) {
+	return r.render(path, content, data, []string{filepath.Clean(path)}, nil)
+}
+
+// RenderFiles renders the rule files of a rule set in order.
+// Templates defined in a file override the blocks of the same name in the files before it,
+// so a rule set can replace sections of the rule set it extends.
+func (r *Renderer) RenderFiles(files []File, data Data) ([][]byte, error) {
+	rendered := make([][]byte, 0, len(files))
+	for i, file := range files {
+		content, err := r.render(file.Path, file.Content, data, []string{filepath.Clean(file.Path)}, files[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to render '%s': %w", file.Path, err)
+		}
+		rendered = append(rendered, content)
+	}
+
+	return rendered, nil
+}
+
+// render renders content, where stack holds the files being rendered, outermost first,
+// and overrides holds the files whose templates replace the blocks in content.
+func (r *Renderer) render(path string, content []byte, data Data, stack []string, overrides []File) ([]byte, error) {
+	if !bytes.Contains(content, []byte("{{")) {
+		return content, nil
+	}
+
+	funcs := Funcs()
+	funcs["include"] = func(name string) (string, error) {
+		return r.include(name, data, stack, overrides)
+	}
+
+	tmpl, err := template.New(path).Funcs(funcs).Option("missingkey=zero").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	for _, override := range overrides {
+		if override.Path == path || !bytes.Contains(override.Content, []byte("{{")) {
+			continue
+		}
+		if _, err := tmpl.New(override.Path).Parse(string(override.Content)); err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// include renders the partial name, relative to the include directory, for the file on top of stack.
+func (r *Renderer) include(name string, data Data, stack []string, overrides []File) (string, error) {
+	if r.IncludeDir == "" {
+		return "", fmt.Errorf("can't include '%s': no include directory", name)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("can't include '%s': the path must be inside %s", name, r.IncludeDir)
+	}
+
+	path := filepath.Join(r.IncludeDir, filepath.FromSlash(name))
+	if slices.Contains(stack, path) {
+		return "", fmt.Errorf("include cycle: %s", strings.Join(append(stack, path), " -> "))
+	}
+
+	readFile := r.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	content, err := readFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	rendered, err := r.render(path, content, data, append(slices.Clip(stack), path), overrides)
+	if err != nil {
+		return "", fmt.Errorf("failed to render '%s': %w", path, err)
+	}
+
+	return string(rendered), nil
+}
+
+// title upper-cases the first letter of s.
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// defaultValue returns value, or def if value is empty.
+func defaultValue(def, value string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
+
+// indent indents every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,200 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashiiiii/airules/pkg/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Render(t *testing.T) {
+	t.Parallel()
+
+	data := Data{
+		Project: project.Info{Name: "airules", Language: "go", Languages: []string{"go", "python"}},
+		Editor:  "claude",
+		Mode:    "local",
+		Key:     "default",
+		Vars:    map[string]string{"team": "platform"},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "Plain content", content: "no actions } here\n", want: "no actions } here\n"},
+		{name: "Project fields", content: "{{ .Project.Name }} uses {{ title .Project.Language }}", want: "airules uses Go"},
+		{name: "Join languages", content: `{{ join ", " .Project.Languages }}`, want: "go, python"},
+		{name: "Condition on a language", content: `{{ if has "python" .Project.Languages }}py{{ end }}`, want: "py"},
+		{name: "Variable", content: "{{ .Vars.team | upper }}", want: "PLATFORM"},
+		{name: "Default for a missing variable", content: `{{ default "none" .Vars.missing }}`, want: "none"},
+		{name: "Editor, mode and key", content: "{{ .Editor }}/{{ .Mode }}/{{ .Key }}", want: "claude/local/default"},
+		{name: "Indent", content: `{{ indent 2 "a\nb" }}`, want: "  a\n  b"},
+		{name: "Parse error", content: "{{ .Project.Name ", wantErr: true},
+		{name: "Unknown function", content: "{{ env \"HOME\" }}", wantErr: true},
+		{name: "Unknown field", content: "{{ .Project.Owner }}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Render("rules.md", []byte(tt.content), data)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_Renderer_include(t *testing.T) {
+	t.Parallel()
+
+	includeDir := filepath.Join("config", "templates")
+	partials := map[string]string{
+		"fragments/security.md": "Never commit secrets in {{ .Project.Name }}.",
+		"fragments/outer.md":    `outer {{ include "fragments/inner.md" }}`,
+		"fragments/inner.md":    "inner",
+		"fragments/self.md":     `{{ include "fragments/self.md" }}`,
+		"fragments/a.md":        `{{ include "fragments/b.md" }}`,
+		"fragments/b.md":        `{{ include "fragments/a.md" }}`,
+		"fragments/broken.md":   "{{ .Project.Name ",
+	}
+	files := make(map[string]string, len(partials))
+	for name, content := range partials {
+		files[filepath.Join(includeDir, filepath.FromSlash(name))] = content
+	}
+	renderer := &Renderer{
+		IncludeDir: includeDir,
+		ReadFile: func(path string) ([]byte, error) {
+			content, ok := files[path]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+
+			return []byte(content), nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr []string
+	}{
+		{
+			name:    "Include a partial",
+			content: "# Rules\n{{ include \"fragments/security.md\" }}",
+			want:    "# Rules\nNever commit secrets in airules.",
+		},
+		{name: "Nested includes", content: `{{ include "fragments/outer.md" }}`, want: "outer inner"},
+		{
+			name:    "Missing partial",
+			content: "line\n{{ include \"fragments/missing.md\" }}",
+			wantErr: []string{"rules.md:2", "fragments/missing.md"},
+		},
+		{
+			name:    "Self include",
+			content: `{{ include "fragments/self.md" }}`,
+			wantErr: []string{"include cycle", "self.md -> " + filepath.Join(includeDir, "fragments", "self.md")},
+		},
+		{name: "Include cycle", content: `{{ include "fragments/a.md" }}`, wantErr: []string{"include cycle", "b.md -> "}},
+		{name: "Broken partial", content: `{{ include "fragments/broken.md" }}`, wantErr: []string{"broken.md", "failed to parse"}},
+		{name: "Path outside the include directory", content: `{{ include "../secret.md" }}`, wantErr: []string{"must be inside"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderer.Render("rules.md", []byte(tt.content), Data{Project: project.Info{Name: "airules"}})
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				for _, want := range tt.wantErr {
+					assert.Contains(t, err.Error(), want)
+				}
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_Renderer_RenderFiles(t *testing.T) {
+	t.Parallel()
+
+	base := File{
+		Path:    "base.md",
+		Content: []byte("# Rules\n{{ block \"testing\" . }}Write unit tests.{{ end }}\n{{ block \"style\" . }}Use gofmt.{{ end }}\n"),
+	}
+
+	tests := []struct {
+		name    string
+		files   []File
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "Blocks keep their defaults",
+			files: []File{base, {Path: "extra.md", Content: []byte("Extra rules.\n")}},
+			want:  []string{"# Rules\nWrite unit tests.\nUse gofmt.\n", "Extra rules.\n"},
+		},
+		{
+			name:  "A later file overrides a block",
+			files: []File{base, {Path: "backend.md", Content: []byte(`{{ define "testing" }}Write integration tests.{{ end }}`)}},
+			want:  []string{"# Rules\nWrite integration tests.\nUse gofmt.\n", ""},
+		},
+		{
+			name: "The last override wins",
+			files: []File{
+				base,
+				{Path: "backend.md", Content: []byte(`{{ define "testing" }}Backend.{{ end }}`)},
+				{Path: "payments.md", Content: []byte(`{{ define "testing" }}Payments.{{ end }}`)},
+			},
+			want: []string{"# Rules\nPayments.\nUse gofmt.\n", "", ""},
+		},
+		{
+			name: "Overrides don't apply to later files",
+			files: []File{
+				{Path: "backend.md", Content: []byte(`{{ define "testing" }}Backend.{{ end }}`)},
+				base,
+			},
+			want: []string{"", "# Rules\nWrite unit tests.\nUse gofmt.\n"},
+		},
+		{
+			name:    "Invalid override",
+			files:   []File{base, {Path: "broken.md", Content: []byte(`{{ define "testing" }}`)}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := (&Renderer{}).RenderFiles(tt.files, Data{})
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			gotStrings := make([]string, 0, len(got))
+			for _, content := range got {
+				gotStrings = append(gotStrings, string(content))
+			}
+			assert.Equal(t, tt.want, gotStrings)
+		})
+	}
+}
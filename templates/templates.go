@@ -0,0 +1,44 @@
+// Package templates embeds the default rule templates shipped with airules.
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS holds the default rule templates, laid out as <editor>/<mode>/<file>.
+//
+//go:embed all:windsurf all:cursor all:claude all:copilot all:agents all:cline all:roo all:gemini all:junie all:zed all:aider
+var FS embed.FS
+
+// Extract writes the embedded templates into destDir, overwriting existing files.
+func Extract(destDir string) error {
+	return fs.WalkDir(FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		destPath := filepath.Join(destDir, filepath.FromSlash(path))
+		if d.IsDir() {
+			if err := os.MkdirAll(destPath, 0o755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", destPath, err)
+			}
+
+			return nil
+		}
+
+		content, err := FS.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read embedded template %s: %w", path, err)
+		}
+
+		if err := os.WriteFile(destPath, content, 0o644); err != nil {
+			return fmt.Errorf("failed to write template %s: %w", destPath, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Extract(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Windsurf local template", path: "windsurf/local/.windsurfrules"},
+		{name: "Windsurf global template", path: "windsurf/global/global_rules.md"},
+		{name: "Cursor local template", path: "cursor/local/project_rules.mdc"},
+		{name: "Cursor global template", path: "cursor/global/global_rules.mdc"},
+		{name: "Claude local template", path: "claude/local/CLAUDE.md"},
+		{name: "Claude global template", path: "claude/global/CLAUDE.md"},
+		{name: "Copilot repository template", path: "copilot/local/copilot-instructions.md"},
+		{name: "Copilot scoped template", path: "copilot/local/tests.instructions.md"},
+		{name: "AGENTS.md template", path: "agents/local/AGENTS.md"},
+		{name: "Cline local template", path: "cline/local/rules.md"},
+		{name: "Cline global template", path: "cline/global/rules.md"},
+		{name: "Roo local template", path: "roo/local/rules.md"},
+		{name: "Roo code mode template", path: "roo/local/rules-code.md"},
+		{name: "Roo global template", path: "roo/global/rules.md"},
+		{name: "Gemini local template", path: "gemini/local/GEMINI.md"},
+		{name: "Gemini global template", path: "gemini/global/GEMINI.md"},
+		{name: "Junie template", path: "junie/local/guidelines.md"},
+		{name: "Zed template", path: "zed/local/.rules"},
+		{name: "Aider local template", path: "aider/local/CONVENTIONS.md"},
+		{name: "Aider global template", path: "aider/global/CONVENTIONS.md"},
+	}
+
+	destDir := t.TempDir()
+	require.NoError(t, Extract(destDir))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			want, err := FS.ReadFile(tt.path)
+			require.NoError(t, err, "Template should be embedded")
+
+			got, err := os.ReadFile(filepath.Join(destDir, filepath.FromSlash(tt.path)))
+			require.NoError(t, err, "Template should be extracted")
+			assert.Equal(t, string(want), string(got), "Extracted template should match the embedded one")
+		})
+	}
+}